@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/diegocsandrim/sonarminer/export"
+	"github.com/diegocsandrim/sonarminer/git"
+	"github.com/diegocsandrim/sonarminer/metrics"
+	"github.com/diegocsandrim/sonarminer/qualityanalyzers"
+	"github.com/diegocsandrim/sonarminer/settings"
+	"github.com/diegocsandrim/sonarminer/strategy"
+)
+
+func runCampaign(ctx context.Context, path string) error {
+	campaign, err := settings.LoadCampaign(path)
+	if err != nil {
+		return err
+	}
+
+	if campaign.Output.Format == "" {
+		campaign.Output.Format = export.FormatCSV
+	}
+	if campaign.Output.Layout == "" {
+		campaign.Output.Layout = export.LayoutLong
+	}
+	if len(campaign.Output.Metrics) == 0 {
+		campaign.Output.Metrics = metrics.DefaultMetrics
+	}
+	err = export.Validate(campaign.Output.Format, campaign.Output.Layout)
+	if err != nil {
+		return err
+	}
+
+	jobs := make([]*repositoryJob, 0, len(campaign.Repositories))
+	seen := make(map[string]string, len(campaign.Repositories))
+	for _, repository := range campaign.Repositories {
+		key := repositoryKey(repository.Repository)
+		if first, exists := seen[key]; exists {
+			if first == repository.Repository {
+				return fmt.Errorf("repository %s is listed more than once", repository.Repository)
+			}
+			return fmt.Errorf("repository %s is listed more than once, as %s", first, repository.Repository)
+		}
+		seen[key] = repository.Repository
+
+		config := campaign.Config(repository)
+		if len(config.Languages) == 0 {
+			config.Languages = git.DefaultLanguages
+		}
+
+		err = validateConfig(config)
+		if err != nil {
+			return fmt.Errorf("repository %s: %w", repository.Repository, err)
+		}
+
+		jobs = append(jobs, &repositoryJob{repository: repository.Repository, config: config})
+	}
+
+	// The options are checked first, a typo should not wait for a token.
+	sonarConfig := campaign.Config(&settings.CampaignRepository{})
+	err = ensureSonarToken(ctx, &sonarConfig)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		job.config.SonarKey = sonarConfig.SonarKey
+	}
+
+	results, err := analyseRepositories(ctx, jobs, campaign.Parallel, campaign.KeepGoing)
+	if err != nil {
+		return err
+	}
+
+	if campaign.Output.Dir != "" && ctx.Err() == nil {
+		err = exportCampaign(ctx, campaign.Output, jobs, results)
+		if err != nil {
+			return err
+		}
+	}
+
+	return analysisFailure(ctx, results)
+}
+
+func validateConfig(config settings.Config) error {
+	err := strategy.Validate(config)
+	if err != nil {
+		return err
+	}
+
+	_, err = git.NewLanguageSet(config.Languages)
+	if err != nil {
+		return err
+	}
+
+	err = git.ValidateHistory(config.History)
+	if err != nil {
+		return err
+	}
+
+	_, err = git.NewBotDetector(config.Bots)
+	if err != nil {
+		return err
+	}
+
+	err = strategy.ValidateNewcomers(config)
+	if err != nil {
+		return err
+	}
+
+	return qualityanalyzers.ValidateProperties(config.SonarProperties)
+}
+
+func exportCampaign(ctx context.Context, output settings.CampaignOutput, jobs []*repositoryJob, results []*repositoryResult) error {
+	measures := make([]*metrics.Measure, 0)
+	for i, result := range results {
+		if result.status != repositoryStatusSucceeded {
+			continue
+		}
+
+		repositoryMeasures, err := collectRepository(ctx, jobs[i].config, jobs[i].repository, output.Metrics)
+		if err != nil {
+			return err
+		}
+		measures = append(measures, repositoryMeasures...)
+	}
+
+	err := os.MkdirAll(output.Dir, 0755)
+	if err != nil {
+		return fmt.Errorf("could not create output directory: %w", err)
+	}
+
+	outputPath := filepath.Join(output.Dir, "measures."+output.Format)
+	log.Printf("writing %d measures to %s", len(measures), outputPath)
+
+	return writeMeasures(outputPath, output.Format, output.Layout, measures)
+}
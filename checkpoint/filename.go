@@ -0,0 +1,19 @@
+package checkpoint
+
+import (
+	"net/url"
+	"strings"
+)
+
+// FileName turns a project key or strategy into a file name. The escaping
+// can be reversed, so two projects never share a file.
+func FileName(name string) string {
+	return url.QueryEscape(name)
+}
+
+// LegacyFileName is the file name of the checkpoints and measures saved
+// before FileName, where owner/a_b and owner_a/b collided. They are still
+// read when there is no file with the new name.
+func LegacyFileName(name string) string {
+	return strings.NewReplacer("/", "_", ":", "_").Replace(name)
+}
@@ -0,0 +1,148 @@
+package checkpoint
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Entry struct {
+	Commit       string    `json:"commit"`
+	Version      string    `json:"version"`
+	Date         time.Time `json:"date"`
+	Contributors int       `json:"contributors"`
+	AnalysedAt   time.Time `json:"analysedAt"`
+}
+
+// file holds the entries with the fingerprint of the options they were
+// planned with, older checkpoints are a plain list of entries.
+type file struct {
+	Options string   `json:"options"`
+	Entries []*Entry `json:"entries"`
+}
+
+type Store struct {
+	path       string
+	legacyPath string
+	options    string
+	entries    map[string]*Entry
+}
+
+func Open(baseDir string, projectKey string, strategy string) (*Store, error) {
+	strategy = strings.ToUpper(strategy)
+	s := Store{
+		path:       path.Join(baseDir, "checkpoints", FileName(projectKey), FileName(strategy)+".json"),
+		legacyPath: path.Join(baseDir, "checkpoints", LegacyFileName(projectKey), LegacyFileName(strategy)+".json"),
+		entries:    make(map[string]*Entry),
+	}
+
+	readPath := s.path
+	data, err := os.ReadFile(readPath)
+	if errors.Is(err, os.ErrNotExist) {
+		readPath = s.legacyPath
+		data, err = os.ReadFile(readPath)
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return &s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fail to read checkpoint file '%s': %w", readPath, err)
+	}
+
+	content := file{}
+	if len(bytes.TrimSpace(data)) > 0 && bytes.TrimSpace(data)[0] == '[' {
+		err = json.Unmarshal(data, &content.Entries)
+	} else {
+		err = json.Unmarshal(data, &content)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint file '%s' is in a bad format: %w", readPath, err)
+	}
+
+	s.options = content.Options
+	for _, entry := range content.Entries {
+		s.entries[entry.Commit] = entry
+	}
+
+	return &s, nil
+}
+
+// Resume keeps the analysed commits when they were planned with the same
+// options, otherwise the commits skipped would come from another plan.
+func (s *Store) Resume(options string) error {
+	if len(s.entries) > 0 && s.options != options {
+		return fmt.Errorf("checkpoint file '%s' was saved with other analysis options, run without --resume to start over", s.path)
+	}
+	s.options = options
+	return nil
+}
+
+func (s *Store) Done(commitHash string) bool {
+	_, exists := s.entries[commitHash]
+	return exists
+}
+
+func (s *Store) Entries() []*Entry {
+	entries := make([]*Entry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Date.Before(entries[j].Date)
+	})
+
+	return entries
+}
+
+func (s *Store) Mark(entry *Entry) error {
+	s.entries[entry.Commit] = entry
+	return s.save()
+}
+
+func (s *Store) Reset(options string) error {
+	s.options = options
+	s.entries = make(map[string]*Entry)
+	for _, filePath := range []string{s.path, s.legacyPath} {
+		err := os.Remove(filePath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("fail to remove checkpoint file '%s': %w", filePath, err)
+		}
+	}
+	return nil
+}
+
+func (s *Store) save() error {
+	err := os.MkdirAll(path.Dir(s.path), 0775)
+	if err != nil {
+		return fmt.Errorf("fail to create checkpoint directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(file{Options: s.options, Entries: s.Entries()}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tempPath := s.path + ".tmp"
+	err = os.WriteFile(tempPath, data, 0664)
+	if err != nil {
+		return fmt.Errorf("fail to write checkpoint file '%s': %w", tempPath, err)
+	}
+
+	err = os.Rename(tempPath, s.path)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(s.legacyPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("fail to remove checkpoint file '%s': %w", s.legacyPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package checkpoint
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResumeNeedsTheSameOptions(t *testing.T) {
+	baseDir := t.TempDir()
+
+	store, err := Open(baseDir, "owner:project", "period")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Reset("interval-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Mark(&Entry{Commit: "a1", Version: "a1", Date: time.Unix(1600000000, 0).UTC()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err = Open(baseDir, "owner:project", "PERIOD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = store.Resume("interval-2"); err == nil {
+		t.Error("Resume accepted a checkpoint saved with other options")
+	}
+	if err = store.Resume("interval-1"); err != nil || !store.Done("a1") {
+		t.Errorf("Resume = %v, Done = %v", err, store.Done("a1"))
+	}
+}
+
+func TestOpenReadsCheckpointsWithoutOptions(t *testing.T) {
+	baseDir := t.TempDir()
+
+	store, err := Open(baseDir, "owner:project", "ALL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.MkdirAll(path.Dir(store.path), 0775)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(store.path, []byte(`[{"commit":"a1","version":"a1","date":"2020-09-13T12:26:40Z"}]`), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err = Open(baseDir, "owner:project", "ALL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(store.Entries()) != 1 {
+		t.Fatalf("Entries = %d, want 1", len(store.Entries()))
+	}
+	if err = store.Resume("options"); err == nil {
+		t.Error("Resume accepted a checkpoint saved without options")
+	}
+}
+
+func TestProjectsDoNotShareCheckpoints(t *testing.T) {
+	baseDir := t.TempDir()
+
+	first, err := Open(baseDir, "a_b:c", "ALL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = first.Mark(&Entry{Commit: "a1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := Open(baseDir, "a:b_c", "ALL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Done("a1") {
+		t.Error("a:b_c read the checkpoint of a_b:c")
+	}
+	err = second.Reset("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err = Open(baseDir, "a_b:c", "ALL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first.Done("a1") {
+		t.Error("resetting a:b_c removed the checkpoint of a_b:c")
+	}
+}
+
+func TestFileName(t *testing.T) {
+	names := []string{"a_b:c", "a:b_c", "gitlab.com:group:sub:project", "local:srv:my_project", "a%3Ab"}
+	seen := make(map[string]string)
+	for _, name := range names {
+		fileName := FileName(name)
+		if other, exists := seen[fileName]; exists {
+			t.Errorf("%s and %s have the same file name %s", name, other, fileName)
+		}
+		seen[fileName] = name
+		if strings.ContainsAny(fileName, "/:") {
+			t.Errorf("FileName(%q) = %q", name, fileName)
+		}
+	}
+}
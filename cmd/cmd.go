@@ -1,12 +1,21 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"strings"
+	"syscall"
+	"time"
 )
 
 type CmdFactory struct {
 	dir string
+	// Env is added to the environment of the current process.
+	Env []string
 }
 
 func NewCmdFactory(dir string) *CmdFactory {
@@ -15,15 +24,108 @@ func NewCmdFactory(dir string) *CmdFactory {
 	}
 }
 
-func (f *CmdFactory) ExecF(command string, params ...interface{}) (string, error) {
-	cmdText := fmt.Sprintf(command, params...)
-	cmd := exec.Command("bash", "-c", cmdText)
-	cmd.Dir = f.dir
+type Output struct {
+	Stdout string
+	Stderr string
+}
+
+type Error struct {
+	Args     []string
+	Dir      string
+	ExitCode int
+	Stderr   string
+	Duration time.Duration
+	Err      error
+}
+
+func (e *Error) Error() string {
+	command := strings.Join(e.Args, " ")
+	if errors.Is(e.Err, context.DeadlineExceeded) {
+		return fmt.Sprintf("command '%s' timed out after %s", command, e.Duration.Round(time.Millisecond))
+	}
+	if errors.Is(e.Err, context.Canceled) {
+		return fmt.Sprintf("command '%s' was canceled", command)
+	}
+
+	message := fmt.Sprintf("command '%s' failed", command)
+	if e.ExitCode >= 0 {
+		message += fmt.Sprintf(" with exit code %d", e.ExitCode)
+	} else {
+		message += ": " + e.Err.Error()
+	}
+
+	stderr := strings.TrimSpace(e.Stderr)
+	if stderr != "" {
+		message += ": " + stderr
+	}
+	return message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Run executes the program with the arguments as they are, no shell is
+// involved. When the context is done the whole process group is killed, so
+// helpers started by the program, like git-remote-https, do not outlive it.
+func (f *CmdFactory) Run(ctx context.Context, name string, args ...string) (*Output, error) {
+	var stdout, stderr bytes.Buffer
+	command := exec.Command(name, args...)
+	command.Dir = f.dir
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+	command.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if len(f.Env) > 0 {
+		command.Env = append(os.Environ(), f.Env...)
+	}
+
+	cmdErr := Error{
+		Args:     append([]string{name}, args...),
+		Dir:      f.dir,
+		ExitCode: -1,
+	}
+
+	start := time.Now()
+	err := ctx.Err()
+	if err == nil {
+		err = command.Start()
+	}
+	if err != nil {
+		cmdErr.Err = err
+		return nil, &cmdErr
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- command.Wait()
+	}()
+
+	killed := false
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		_ = syscall.Kill(-command.Process.Pid, syscall.SIGKILL)
+		killed = true
+		err = <-done
+	}
+
+	output := Output{Stdout: stdout.String(), Stderr: stderr.String()}
+	cmdErr.Duration = time.Since(start)
+	cmdErr.Stderr = output.Stderr
+
+	if killed {
+		cmdErr.Err = ctx.Err()
+		return &output, &cmdErr
+	}
 
-	out, err := cmd.CombinedOutput() //may need to separate stdout and stderr
 	if err != nil {
-		return string(out), err
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			cmdErr.ExitCode = exitErr.ExitCode()
+		}
+		cmdErr.Err = err
+		return &output, &cmdErr
 	}
 
-	return string(out), nil
+	return &output, nil
 }
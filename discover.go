@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/diegocsandrim/sonarminer/github"
+	"github.com/urfave/cli/v2"
+)
+
+func discoverFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "github-url",
+			Usage:   "GitHub API URL, to use GitHub Enterprise or a local stand-in",
+			EnvVars: []string{"GITHUB_API_URL"},
+			Value:   github.DefaultBaseURL,
+		},
+		&cli.StringFlag{
+			Name:    "github-token",
+			Usage:   "GitHub token, raises the search rate limit",
+			EnvVars: []string{"GITHUB_TOKEN"},
+		},
+		&cli.StringSliceFlag{
+			Name:  "language",
+			Usage: "Primary language of the repositories, any of the given ones",
+		},
+		&cli.IntFlag{
+			Name:  "min-stars",
+			Usage: "Minimum number of stars",
+		},
+		&cli.IntFlag{
+			Name:  "max-stars",
+			Usage: "Maximum number of stars",
+		},
+		&cli.StringFlag{
+			Name:  "created-after",
+			Usage: "Only repositories created on or after the date, in format YYYY-MM-DD",
+		},
+		&cli.StringFlag{
+			Name:  "created-before",
+			Usage: "Only repositories created on or before the date, in format YYYY-MM-DD",
+		},
+		&cli.StringFlag{
+			Name:  "pushed-after",
+			Usage: "Only repositories pushed on or after the date, in format YYYY-MM-DD",
+		},
+		&cli.BoolFlag{
+			Name:  "include-forks",
+			Usage: "Also search forks",
+		},
+		&cli.BoolFlag{
+			Name:  "include-archived",
+			Usage: "Also search archived repositories",
+		},
+		&cli.StringFlag{
+			Name:  "query",
+			Usage: "Extra GitHub search qualifiers, e.g. 'topic:cli license:mit'",
+		},
+		&cli.StringFlag{
+			Name:  "sort",
+			Usage: "Sort by stars, forks or updated, or empty for best match",
+			Value: "stars",
+		},
+		&cli.IntFlag{
+			Name:  "limit",
+			Usage: "Maximum number of repositories, GitHub returns at most 1000 per query",
+			Value: 100,
+		},
+		&cli.StringFlag{
+			Name:  "output",
+			Usage: "File to write the repositories to, defaults to stdout",
+		},
+	}
+}
+
+func discoverRepositories(c *cli.Context) error {
+	query := github.SearchQuery{
+		Languages:       c.StringSlice("language"),
+		MinStars:        c.Int("min-stars"),
+		MaxStars:        c.Int("max-stars"),
+		CreatedAfter:    c.String("created-after"),
+		CreatedBefore:   c.String("created-before"),
+		PushedAfter:     c.String("pushed-after"),
+		IncludeForks:    c.Bool("include-forks"),
+		IncludeArchived: c.Bool("include-archived"),
+		Extra:           c.String("query"),
+	}
+	err := query.Validate()
+	if err != nil {
+		return err
+	}
+
+	switch c.String("sort") {
+	case "", "stars", "forks", "updated":
+	default:
+		return fmt.Errorf("unknown sort: %s, must be one of: stars, forks, updated", c.String("sort"))
+	}
+
+	log.Printf("searching github repositories: %s", query)
+
+	client := github.NewClient(c.String("github-url"), c.String("github-token"))
+	repositories, err := client.SearchRepositories(c.Context, query, c.String("sort"), c.Int("limit"))
+	if err != nil {
+		return err
+	}
+
+	log.Printf("found %d repositories", len(repositories))
+
+	if c.String("output") == "" {
+		return writeRepositories(os.Stdout, query, repositories)
+	}
+
+	output, err := os.Create(c.String("output"))
+	if err != nil {
+		return fmt.Errorf("could not create output file: %w", err)
+	}
+
+	err = writeRepositories(output, query, repositories)
+	if err != nil {
+		output.Close()
+		return err
+	}
+
+	return output.Close()
+}
+
+// writeRepositories writes the repositories in the --repos-file format, with
+// the query and date as comments so the sample can be traced back.
+func writeRepositories(w io.Writer, query github.SearchQuery, repositories []*github.Repository) error {
+	_, err := fmt.Fprintf(w, "# github search: %s\n# searched at: %s\n", query, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+
+	for _, repository := range repositories {
+		_, err = fmt.Fprintf(w, "%s # stars: %d, language: %s, created: %s, pushed: %s\n",
+			repository.FullName,
+			repository.Stars,
+			repository.Language,
+			repository.CreatedAt.Format("2006-01-02"),
+			repository.PushedAt.Format("2006-01-02"),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
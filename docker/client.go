@@ -0,0 +1,261 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const DefaultHost = "unix:///var/run/docker.sock"
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewClient(host string) (*Client, error) {
+	hostURL, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("docker host is in a bad format: '%s': %w", host, err)
+	}
+
+	c := Client{}
+
+	switch hostURL.Scheme {
+	case "unix":
+		socketPath := hostURL.Path
+		dialer := net.Dialer{}
+		c.baseURL = "http://docker"
+		c.httpClient = &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _ string, _ string) (net.Conn, error) {
+					return dialer.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		}
+	case "tcp", "http":
+		c.baseURL = "http://" + hostURL.Host
+		c.httpClient = &http.Client{}
+	default:
+		return nil, fmt.Errorf("unsupported docker host scheme: '%s'", hostURL.Scheme)
+	}
+
+	return &c, nil
+}
+
+func NewClientFromEnv() (*Client, error) {
+	host := os.Getenv("DOCKER_HOST")
+	if host == "" {
+		host = DefaultHost
+	}
+	return NewClient(host)
+}
+
+func (c *Client) CreateContainer(ctx context.Context, name string, config *ContainerConfig) (string, error) {
+	query := url.Values{}
+	query.Set("name", name)
+
+	created := struct {
+		Id       string
+		Warnings []string
+	}{}
+
+	err := c.doJSON(ctx, "create container", http.MethodPost, "/containers/create", query, config, &created)
+	if err != nil {
+		return "", err
+	}
+
+	return created.Id, nil
+}
+
+func (c *Client) StartContainer(ctx context.Context, id string) error {
+	res, err := c.do(ctx, "start container", http.MethodPost, "/containers/"+id+"/start", nil, nil)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+func (c *Client) StopContainer(ctx context.Context, id string, timeout time.Duration) error {
+	query := url.Values{}
+	query.Set("t", fmt.Sprint(int(timeout.Seconds())))
+
+	res, err := c.do(ctx, "stop container", http.MethodPost, "/containers/"+id+"/stop", query, nil)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+func (c *Client) WaitContainer(ctx context.Context, id string) (int, error) {
+	waited := struct {
+		StatusCode int
+		Error      *struct {
+			Message string
+		}
+	}{}
+
+	err := c.doJSON(ctx, "wait container", http.MethodPost, "/containers/"+id+"/wait", nil, nil, &waited)
+	if err != nil {
+		return 0, err
+	}
+
+	if waited.Error != nil && waited.Error.Message != "" {
+		return waited.StatusCode, &Error{Operation: "wait container", Message: waited.Error.Message}
+	}
+
+	return waited.StatusCode, nil
+}
+
+func (c *Client) ContainerLogs(ctx context.Context, id string, follow bool, stdout io.Writer, stderr io.Writer) error {
+	query := url.Values{}
+	query.Set("stdout", "1")
+	query.Set("stderr", "1")
+	if follow {
+		query.Set("follow", "1")
+	}
+
+	res, err := c.do(ctx, "container logs", http.MethodGet, "/containers/"+id+"/logs", query, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	return demultiplex(res.Body, stdout, stderr)
+}
+
+func (c *Client) RemoveContainer(ctx context.Context, id string, force bool) error {
+	query := url.Values{}
+	if force {
+		query.Set("force", "1")
+	}
+
+	res, err := c.do(ctx, "remove container", http.MethodDelete, "/containers/"+id, query, nil)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+func (c *Client) ListContainers(ctx context.Context, label string) ([]*Container, error) {
+	filters, err := json.Marshal(map[string][]string{"label": {label}})
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("all", "1")
+	query.Set("filters", string(filters))
+
+	containers := make([]*Container, 0)
+	err = c.doJSON(ctx, "list containers", http.MethodGet, "/containers/json", query, nil, &containers)
+	if err != nil {
+		return nil, err
+	}
+
+	return containers, nil
+}
+
+func (c *Client) ImageExists(ctx context.Context, image string) (bool, error) {
+	res, err := c.do(ctx, "inspect image", http.MethodGet, "/images/"+image+"/json", nil, nil)
+	if IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, res.Body.Close()
+}
+
+func (c *Client) PullImage(ctx context.Context, image string) error {
+	name, tag := image, "latest"
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		name, tag = image[:i], image[i+1:]
+	}
+
+	query := url.Values{}
+	query.Set("fromImage", name)
+	query.Set("tag", tag)
+
+	res, err := c.do(ctx, "pull image", http.MethodPost, "/images/create", query, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	decoder := json.NewDecoder(res.Body)
+	for {
+		progress := struct {
+			Error string `json:"error"`
+		}{}
+
+		err = decoder.Decode(&progress)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("fail to read image pull progress: %w", err)
+		}
+		if progress.Error != "" {
+			return &Error{Operation: "pull image", Message: progress.Error}
+		}
+	}
+}
+
+func (c *Client) doJSON(ctx context.Context, operation string, method string, path string, query url.Values, body interface{}, result interface{}) error {
+	res, err := c.do(ctx, operation, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	err = json.NewDecoder(res.Body).Decode(result)
+	if err != nil {
+		return fmt.Errorf("fail to decode %s response: %w", operation, err)
+	}
+	return nil
+}
+
+func (c *Client) do(ctx context.Context, operation string, method string, path string, query url.Values, body interface{}) (*http.Response, error) {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	reqURL := c.baseURL + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("fail to create a request to %s: %w", operation, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fail to %s: %w", operation, err)
+	}
+
+	if res.StatusCode >= 400 {
+		defer res.Body.Close()
+		return nil, newError(operation, res)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,132 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient("tcp://" + server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestClientRunsAContainer(t *testing.T) {
+	calls := []string{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/create", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "create")
+		config := ContainerConfig{}
+		if err := json.NewDecoder(r.Body).Decode(&config); err != nil || config.Image != "scanner:1" {
+			http.Error(w, `{"message":"bad config"}`, http.StatusBadRequest)
+			return
+		}
+		if r.URL.Query().Get("name") != "scan" {
+			http.Error(w, `{"message":"bad name"}`, http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"Id":"c1","Warnings":[]}`))
+	})
+	mux.HandleFunc("/containers/c1/start", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "start")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/containers/c1/wait", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "wait")
+		w.Write([]byte(`{"StatusCode":3}`))
+	})
+	mux.HandleFunc("/containers/c1/logs", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "logs")
+		w.Write(frames(frame(streamStdout, "out"), frame(streamStderr, "err")))
+	})
+	mux.HandleFunc("/containers/c1", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" force="+r.URL.Query().Get("force"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	client := newTestClient(t, mux)
+	ctx := context.Background()
+
+	id, err := client.CreateContainer(ctx, "scan", &ContainerConfig{Image: "scanner:1"})
+	if err != nil || id != "c1" {
+		t.Fatalf("CreateContainer = %q, %v", id, err)
+	}
+	if err := client.StartContainer(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	status, err := client.WaitContainer(ctx, id)
+	if err != nil || status != 3 {
+		t.Fatalf("WaitContainer = %d, %v", status, err)
+	}
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	if err := client.ContainerLogs(ctx, id, false, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != "out" || stderr.String() != "err" {
+		t.Errorf("ContainerLogs = %q, %q", stdout.String(), stderr.String())
+	}
+	if err := client.RemoveContainer(ctx, id, true); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"create", "start", "wait", "logs", "DELETE force=1"}
+	if len(calls) != len(want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("calls = %v, want %v", calls, want)
+			break
+		}
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/images/missing:1/json", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"no such image"}`, http.StatusNotFound)
+	})
+	mux.HandleFunc("/images/present:1/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/containers/busy/start", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"container is busy"}`, http.StatusConflict)
+	})
+	mux.HandleFunc("/images/create", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"Pulling"}` + "\n" + `{"error":"manifest unknown"}`))
+	})
+	client := newTestClient(t, mux)
+	ctx := context.Background()
+
+	exists, err := client.ImageExists(ctx, "missing:1")
+	if err != nil || exists {
+		t.Errorf("ImageExists(missing) = %v, %v", exists, err)
+	}
+	exists, err = client.ImageExists(ctx, "present:1")
+	if err != nil || !exists {
+		t.Errorf("ImageExists(present) = %v, %v", exists, err)
+	}
+
+	err = client.StartContainer(ctx, "busy")
+	dockerErr, ok := err.(*Error)
+	if !ok || dockerErr.StatusCode != http.StatusConflict || dockerErr.Message != "container is busy" {
+		t.Errorf("StartContainer(busy) = %v", err)
+	}
+	if IsNotFound(err) {
+		t.Errorf("IsNotFound(%v) = true", err)
+	}
+
+	err = client.PullImage(ctx, "scanner:1")
+	if err == nil || err.Error() != "docker failed to pull image: manifest unknown" {
+		t.Errorf("PullImage = %v", err)
+	}
+}
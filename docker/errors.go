@@ -0,0 +1,46 @@
+package docker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+type Error struct {
+	Operation  string
+	StatusCode int
+	Message    string
+}
+
+func newError(operation string, res *http.Response) *Error {
+	e := Error{
+		Operation:  operation,
+		StatusCode: res.StatusCode,
+	}
+
+	body, _ := io.ReadAll(res.Body)
+	message := struct {
+		Message string `json:"message"`
+	}{}
+	if json.Unmarshal(body, &message) == nil && message.Message != "" {
+		e.Message = message.Message
+	} else {
+		e.Message = string(body)
+	}
+
+	return &e
+}
+
+func (e *Error) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("docker failed to %s: %s", e.Operation, e.Message)
+	}
+	return fmt.Sprintf("docker failed to %s, status code %d: %s", e.Operation, e.StatusCode, e.Message)
+}
+
+func IsNotFound(err error) bool {
+	var dockerErr *Error
+	return errors.As(err, &dockerErr) && dockerErr.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,44 @@
+package docker
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	streamStdout = 1
+	streamStderr = 2
+)
+
+// demultiplex splits the stdout and stderr frames of a container started
+// without a TTY, each frame has an 8 bytes header: stream type, 3 bytes of
+// padding and the big endian payload size.
+func demultiplex(r io.Reader, stdout io.Writer, stderr io.Writer) error {
+	header := make([]byte, 8)
+	for {
+		_, err := io.ReadFull(r, header)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("fail to read log frame header: %w", err)
+		}
+
+		var w io.Writer
+		switch header[0] {
+		case streamStdout:
+			w = stdout
+		case streamStderr:
+			w = stderr
+		default:
+			w = io.Discard
+		}
+
+		size := int64(binary.BigEndian.Uint32(header[4:]))
+		_, err = io.CopyN(w, r, size)
+		if err != nil {
+			return fmt.Errorf("fail to read log frame: %w", err)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package docker
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func frame(stream byte, payload string) []byte {
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	return append(header, payload...)
+}
+
+func frames(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+func TestDemultiplex(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []byte
+		stdout string
+		stderr string
+		fails  bool
+	}{
+		{"empty", nil, "", "", false},
+		{"stdout", frame(streamStdout, "hello\n"), "hello\n", "", false},
+		{"interleaved", frames(frame(streamStdout, "a"), frame(streamStderr, "b"), frame(streamStdout, "c")), "ac", "b", false},
+		{"empty frame", frames(frame(streamStderr, ""), frame(streamStdout, "a")), "a", "", false},
+		{"stdin discarded", frames(frame(0, "ignored"), frame(streamStdout, "a")), "a", "", false},
+		{"truncated header", frame(streamStdout, "a")[:5], "", "", true},
+		{"truncated payload", frame(streamStdout, "hello")[:10], "he", "", true},
+	}
+
+	for _, test := range tests {
+		stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+		err := demultiplex(bytes.NewReader(test.input), &stdout, &stderr)
+		if test.fails != (err != nil) {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+		}
+		if stdout.String() != test.stdout {
+			t.Errorf("%s: stdout = %q, want %q", test.name, stdout.String(), test.stdout)
+		}
+		if stderr.String() != test.stderr {
+			t.Errorf("%s: stderr = %q, want %q", test.name, stderr.String(), test.stderr)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package docker
+
+type ContainerConfig struct {
+	Image      string
+	Cmd        []string          `json:",omitempty"`
+	Entrypoint []string          `json:",omitempty"`
+	Env        []string          `json:",omitempty"`
+	Labels     map[string]string `json:",omitempty"`
+	WorkingDir string            `json:",omitempty"`
+	HostConfig *HostConfig       `json:",omitempty"`
+}
+
+type HostConfig struct {
+	Binds       []string `json:",omitempty"`
+	NetworkMode string   `json:",omitempty"`
+}
+
+type Container struct {
+	Id     string
+	Names  []string
+	Image  string
+	State  string
+	Labels map[string]string
+}
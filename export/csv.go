@@ -0,0 +1,37 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+func writeCSV(w io.Writer, t *table) error {
+	writer := csv.NewWriter(w)
+
+	header := make([]string, 0, len(t.columns))
+	for _, c := range t.columns {
+		header = append(header, c.name)
+	}
+
+	err := writer.Write(header)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range t.rows {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			if cell != nil {
+				record[i] = *cell
+			}
+		}
+
+		err = writer.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,157 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/diegocsandrim/sonarminer/metrics"
+)
+
+const (
+	FormatCSV     = "csv"
+	FormatJSONL   = "jsonl"
+	FormatParquet = "parquet"
+
+	LayoutLong = "long"
+	LayoutWide = "wide"
+)
+
+type columnKind int
+
+const (
+	stringColumn columnKind = iota
+	intColumn
+	timestampColumn
+	numberColumn
+)
+
+type column struct {
+	name string
+	kind columnKind
+}
+
+type table struct {
+	columns []column
+	rows    [][]*string
+}
+
+var analysisColumns = []column{
+	{name: "project", kind: stringColumn},
+	{name: "commit", kind: stringColumn},
+	{name: "project_version", kind: stringColumn},
+	{name: "project_date", kind: timestampColumn},
+	{name: "contributors", kind: intColumn},
+}
+
+func Validate(format string, layout string) error {
+	switch layout {
+	case LayoutLong, LayoutWide:
+	default:
+		return fmt.Errorf("unknown layout: %s, must be one of: %s, %s", layout, LayoutLong, LayoutWide)
+	}
+
+	switch format {
+	case FormatCSV, FormatJSONL, FormatParquet:
+	default:
+		return fmt.Errorf("unknown format: %s, must be one of: %s, %s, %s", format, FormatCSV, FormatJSONL, FormatParquet)
+	}
+
+	return nil
+}
+
+func Write(w io.Writer, format string, layout string, measures []*metrics.Measure) error {
+	var t *table
+	switch layout {
+	case LayoutLong:
+		t = longTable(measures)
+	case LayoutWide:
+		t = wideTable(measures)
+	default:
+		return fmt.Errorf("unknown layout: %s, must be one of: %s, %s", layout, LayoutLong, LayoutWide)
+	}
+
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, t)
+	case FormatJSONL:
+		return writeJSONL(w, t)
+	case FormatParquet:
+		return writeParquet(w, t)
+	default:
+		return fmt.Errorf("unknown format: %s, must be one of: %s, %s, %s", format, FormatCSV, FormatJSONL, FormatParquet)
+	}
+}
+
+func longTable(measures []*metrics.Measure) *table {
+	t := table{
+		columns: append(append([]column{}, analysisColumns...),
+			column{name: "metric", kind: stringColumn},
+			column{name: "value", kind: numberColumn},
+		),
+		rows: make([][]*string, 0, len(measures)),
+	}
+
+	for _, measure := range measures {
+		row := append(analysisCells(measure), &measure.Metric, &measure.Value)
+		t.rows = append(t.rows, row)
+	}
+
+	return &t
+}
+
+func wideTable(measures []*metrics.Measure) *table {
+	metricIndexes := make(map[string]int)
+	metricNames := make([]string, 0)
+	for _, measure := range measures {
+		if _, exists := metricIndexes[measure.Metric]; !exists {
+			metricIndexes[measure.Metric] = 0
+			metricNames = append(metricNames, measure.Metric)
+		}
+	}
+	sort.Strings(metricNames)
+
+	t := table{
+		columns: append([]column{}, analysisColumns...),
+		rows:    make([][]*string, 0),
+	}
+	for i, metricName := range metricNames {
+		metricIndexes[metricName] = len(analysisColumns) + i
+		t.columns = append(t.columns, column{name: metricName, kind: numberColumn})
+	}
+
+	rowsByAnalysis := make(map[string][]*string)
+	for _, measure := range measures {
+		analysisKey := fmt.Sprintf("%s/%s/%d", measure.Project, measure.Version, measure.ProjectDate.Unix())
+		row, exists := rowsByAnalysis[analysisKey]
+		if !exists {
+			row = append(analysisCells(measure), make([]*string, len(metricNames))...)
+			rowsByAnalysis[analysisKey] = row
+			t.rows = append(t.rows, row)
+		}
+
+		row[metricIndexes[measure.Metric]] = &measure.Value
+	}
+
+	return &t
+}
+
+func analysisCells(measure *metrics.Measure) []*string {
+	projectDate := measure.ProjectDate.UTC().Format(time.RFC3339)
+
+	var contributors *string
+	if measure.Contributors != nil {
+		value := strconv.Itoa(*measure.Contributors)
+		contributors = &value
+	}
+
+	return []*string{
+		&measure.Project,
+		&measure.Commit,
+		&measure.Version,
+		&projectDate,
+		contributors,
+	}
+}
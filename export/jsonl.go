@@ -0,0 +1,50 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+func writeJSONL(w io.Writer, t *table) error {
+	writer := bufio.NewWriter(w)
+
+	for _, row := range t.rows {
+		record := make(map[string]interface{}, len(t.columns))
+		for i, cell := range row {
+			record[t.columns[i].name] = jsonValue(t.columns[i].kind, cell)
+		}
+
+		line, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+
+		_, err = writer.Write(append(line, '\n'))
+		if err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
+
+func jsonValue(kind columnKind, cell *string) interface{} {
+	if cell == nil {
+		return nil
+	}
+
+	switch kind {
+	case intColumn:
+		if value, err := strconv.Atoi(*cell); err == nil {
+			return value
+		}
+	case numberColumn:
+		if value, err := strconv.ParseFloat(*cell, 64); err == nil {
+			return value
+		}
+	}
+
+	return *cell
+}
@@ -0,0 +1,106 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+func writeParquet(w io.Writer, t *table) error {
+	columns := parquetColumns(t)
+	schema := make([]string, 0, len(columns))
+	for _, c := range columns {
+		schema = append(schema, parquetSchema(c))
+	}
+
+	parquetWriter, err := writer.NewCSVWriterFromWriter(schema, w, 1)
+	if err != nil {
+		return fmt.Errorf("could not create parquet writer: %w", err)
+	}
+
+	for _, row := range t.rows {
+		record := make([]*string, len(row))
+		for i, cell := range row {
+			record[i], err = parquetValue(columns[i].kind, cell)
+			if err != nil {
+				return fmt.Errorf("column %s: %w", columns[i].name, err)
+			}
+		}
+
+		err = parquetWriter.WriteString(record)
+		if err != nil {
+			return fmt.Errorf("could not write parquet row: %w", err)
+		}
+	}
+
+	err = parquetWriter.WriteStop()
+	if err != nil {
+		return fmt.Errorf("could not finish parquet file: %w", err)
+	}
+
+	return nil
+}
+
+// parquetColumns types the number columns with a non numeric value, like
+// alert_status or a rating letter, as text so no value is lost.
+func parquetColumns(t *table) []column {
+	columns := make([]column, len(t.columns))
+	copy(columns, t.columns)
+
+	for i, c := range columns {
+		if c.kind != numberColumn {
+			continue
+		}
+		for _, row := range t.rows {
+			if row[i] == nil {
+				continue
+			}
+			if _, err := strconv.ParseFloat(*row[i], 64); err != nil {
+				columns[i].kind = stringColumn
+				break
+			}
+		}
+	}
+
+	return columns
+}
+
+func parquetSchema(c column) string {
+	switch c.kind {
+	case intColumn:
+		return fmt.Sprintf("name=%s, type=INT32, repetitiontype=OPTIONAL", c.name)
+	case timestampColumn:
+		return fmt.Sprintf("name=%s, type=INT64, convertedtype=TIMESTAMP_MILLIS, repetitiontype=OPTIONAL", c.name)
+	case numberColumn:
+		return fmt.Sprintf("name=%s, type=DOUBLE, repetitiontype=OPTIONAL", c.name)
+	default:
+		return fmt.Sprintf("name=%s, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL", c.name)
+	}
+}
+
+// parquetValue converts the cell to the text representation parquet-go
+// expects for the column type.
+func parquetValue(kind columnKind, cell *string) (*string, error) {
+	if cell == nil {
+		return nil, nil
+	}
+
+	switch kind {
+	case timestampColumn:
+		date, err := time.Parse(time.RFC3339, *cell)
+		if err != nil {
+			return nil, err
+		}
+		millis := strconv.FormatInt(date.UnixNano()/int64(time.Millisecond), 10)
+		return &millis, nil
+	case numberColumn:
+		if _, err := strconv.ParseFloat(*cell, 64); err != nil {
+			return nil, fmt.Errorf("value is not a number: '%s'", *cell)
+		}
+	}
+
+	return cell, nil
+}
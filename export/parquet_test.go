@@ -0,0 +1,83 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diegocsandrim/sonarminer/metrics"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+func TestParquetKeepsNonNumericValues(t *testing.T) {
+	measures := []*metrics.Measure{
+		{Project: "p", Version: "v1", ProjectDate: time.Date(2021, 1, 10, 0, 0, 0, 0, time.UTC), Metric: "ncloc", Value: "10"},
+		{Project: "p", Version: "v1", ProjectDate: time.Date(2021, 1, 10, 0, 0, 0, 0, time.UTC), Metric: "alert_status", Value: "OK"},
+	}
+
+	tests := []struct {
+		layout string
+		column string
+		kind   columnKind
+	}{
+		{LayoutLong, "value", stringColumn},
+		{LayoutWide, "alert_status", stringColumn},
+		{LayoutWide, "ncloc", numberColumn},
+	}
+
+	for _, test := range tests {
+		t.Run(test.layout+"/"+test.column, func(t *testing.T) {
+			measuresTable := longTable(measures)
+			if test.layout == LayoutWide {
+				measuresTable = wideTable(measures)
+			}
+
+			for _, c := range parquetColumns(measuresTable) {
+				if c.name == test.column && c.kind != test.kind {
+					t.Errorf("column %s has kind %d, want %d", c.name, c.kind, test.kind)
+				}
+			}
+
+			var output bytes.Buffer
+			err := Write(&output, FormatParquet, test.layout, measures)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			file, err := buffer.NewBufferFile(output.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			parquetReader, err := reader.NewParquetColumnReader(file, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer parquetReader.ReadStop()
+
+			for _, path := range parquetReader.SchemaHandler.ValueColumns {
+				if !strings.HasSuffix(strings.ToLower(path), "\x01"+strings.ToLower(test.column)) {
+					continue
+				}
+				values, _, _, err := parquetReader.ReadColumnByPath(path, parquetReader.GetNumRows())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if test.kind == stringColumn && !containsValue(values, "OK") {
+					t.Errorf("column %s = %v, want the OK value", test.column, values)
+				}
+			}
+		})
+	}
+}
+
+func containsValue(values []interface{}, want string) bool {
+	for _, value := range values {
+		if fmt.Sprint(value) == want {
+			return true
+		}
+	}
+	return false
+}
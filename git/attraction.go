@@ -0,0 +1,197 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// AttractionParent attributes each newcomer to the commit their first
+	// commit was based on.
+	AttractionParent = "parent"
+	// AttractionDays attributes the newcomers to the state of the project at
+	// the start of consecutive windows of days.
+	AttractionDays = "days"
+	// AttractionRelease attributes the newcomers to the last release before
+	// they joined.
+	AttractionRelease = "release"
+)
+
+type AttractionModel struct {
+	Mode string
+	Days int
+}
+
+func (m AttractionModel) Validate() error {
+	switch m.Mode {
+	case "", AttractionParent, AttractionRelease:
+		return nil
+	case AttractionDays:
+		if m.Days <= 0 {
+			return fmt.Errorf("attraction window must have at least one day")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown attraction model: %s, must be one of: %s, %s, %s", m.Mode, AttractionParent, AttractionDays, AttractionRelease)
+	}
+}
+
+// AttractionWindow is a period of the project history with the newcomers
+// that joined during it. Commit is the state of the project when the window
+// started, the release commit for release windows.
+type AttractionWindow struct {
+	Start        time.Time
+	End          time.Time
+	Release      string
+	Commit       *Commit
+	Contributors []*Contributor
+}
+
+// AttractionWindows splits the history in windows by the attraction model,
+// from the oldest. With the parent model there is a window per attractor
+// commit.
+func (g *GitRepo) AttractionWindows() []*AttractionWindow {
+	var windows []*AttractionWindow
+	switch g.attraction.Mode {
+	case AttractionDays:
+		windows = g.dayWindows(g.attraction.Days)
+	case AttractionRelease:
+		windows = g.releaseWindows()
+	default:
+		return g.parentWindows()
+	}
+
+	for _, contributor := range g.contributors {
+		if !g.CountsAsContributor(contributor) {
+			continue
+		}
+
+		entranceCommit := g.newcomers.EntranceCommit(contributor)
+		if entranceCommit.ParentHash == "" {
+			continue
+		}
+
+		// The window the newcomer joined in is the last one started before,
+		// a release made by the newcomer did not attract them.
+		joined := g.arrivalDate(entranceCommit)
+		i := sort.Search(len(windows), func(i int) bool {
+			return !windows[i].Start.Before(joined)
+		}) - 1
+		if i < 0 {
+			continue
+		}
+		windows[i].Contributors = append(windows[i].Contributors, contributor)
+	}
+
+	return windows
+}
+
+func (g *GitRepo) parentWindows() []*AttractionWindow {
+	contributorAttractorCommits := g.parentAttractorCommits()
+	sort.Slice(contributorAttractorCommits, func(i, j int) bool {
+		return contributorAttractorCommits[i].Commit.Id < contributorAttractorCommits[j].Commit.Id
+	})
+
+	windows := make([]*AttractionWindow, 0, len(contributorAttractorCommits))
+	for i, contributorAttractorCommit := range contributorAttractorCommits {
+		end := g.head.Date
+		if i+1 < len(contributorAttractorCommits) {
+			end = contributorAttractorCommits[i+1].Commit.Date
+		}
+		windows = append(windows, &AttractionWindow{
+			Start:        contributorAttractorCommit.Commit.Date,
+			End:          end,
+			Commit:       contributorAttractorCommit.Commit,
+			Contributors: contributorAttractorCommit.Contributors,
+		})
+	}
+	return windows
+}
+
+// dayWindows tiles the history from the first mainline commit, the last
+// window ends after HEAD.
+func (g *GitRepo) dayWindows(days int) []*AttractionWindow {
+	windows := make([]*AttractionWindow, 0)
+	if len(g.mainline) == 0 {
+		return windows
+	}
+
+	last := g.lastDate()
+	for start := g.firstDate(); !start.After(last); start = start.AddDate(0, 0, days) {
+		windows = append(windows, &AttractionWindow{
+			Start:  start,
+			End:    start.AddDate(0, 0, days),
+			Commit: g.stateAt(start),
+		})
+	}
+	return windows
+}
+
+// releaseWindows starts a window at each tag, the newcomers before the first
+// release are attributed to the first commit.
+func (g *GitRepo) releaseWindows() []*AttractionWindow {
+	windows := make([]*AttractionWindow, 0, len(g.tags)+1)
+	if len(g.mainline) == 0 {
+		return windows
+	}
+
+	end := g.lastDate().Add(time.Second)
+	first := g.firstDate()
+	if len(g.tags) == 0 || first.Before(g.tags[0].Date) {
+		windows = append(windows, &AttractionWindow{Start: first, End: end, Commit: g.mainline[0]})
+	}
+
+	for _, tag := range g.tags {
+		if len(windows) > 0 {
+			windows[len(windows)-1].End = tag.Date
+		}
+		windows = append(windows, &AttractionWindow{Start: tag.Date, End: end, Release: tag.Name, Commit: tag.Commit})
+	}
+
+	return windows
+}
+
+// arrivalDate is when the commit reached the analysed history, the date it
+// landed on the mainline in mainline history.
+func (g *GitRepo) arrivalDate(commit *Commit) time.Time {
+	if g.history == HistoryMainline {
+		if landing := g.LandingCommit(commit); landing != nil {
+			return landing.Date
+		}
+	}
+	return commit.Date
+}
+
+// stateAt returns the newest mainline commit at the date, the first commit
+// for older dates.
+func (g *GitRepo) stateAt(date time.Time) *Commit {
+	state := g.mainline[0]
+	for _, commit := range g.mainline {
+		if commit.Date.After(date) {
+			break
+		}
+		state = commit
+	}
+	return state
+}
+
+func (g *GitRepo) firstDate() time.Time {
+	first := g.head.Date
+	for _, commit := range g.commits {
+		if commit.Date.Before(first) {
+			first = commit.Date
+		}
+	}
+	return first
+}
+
+func (g *GitRepo) lastDate() time.Time {
+	last := g.head.Date
+	for _, commit := range g.commits {
+		if commit.Date.After(last) {
+			last = commit.Date
+		}
+	}
+	return last
+}
@@ -0,0 +1,106 @@
+package git
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// botNames are automation accounts that commit with their plain name, the
+// GitHub apps are also caught by the [bot] suffix.
+var botNames = []string{
+	"dependabot", "dependabot-preview", "renovate", "renovate-bot", "renovatebot",
+	"github-actions", "greenkeeper", "greenkeeperio-bot", "snyk-bot", "imgbot",
+	"mergify", "pre-commit-ci", "allcontributors", "semantic-release-bot",
+	"codecov", "travis-ci", "circleci", "jenkins", "gitlab-ci", "k8s-ci-robot",
+	"fossabot", "whitesource-bolt", "deepsource-autofix", "copybara", "weblate",
+}
+
+var botEmails = []string{
+	"action@github.com",
+	"actions@github.com",
+	"bot@renovateapp.com",
+	"support@dependabot.com",
+	"noreply@weblate.org",
+}
+
+// botPattern matches names and email users like ci-bot, release_bot or
+// build.bot, but not robot or abbot.
+var botPattern = regexp.MustCompile(`(\[bot\]|[-_.\s]bot|^bot)$`)
+
+// BotDetector tells the automation accounts apart from the contributors,
+// using the built-in names plus the given patterns.
+type BotDetector struct {
+	names    map[string]interface{}
+	emails   map[string]interface{}
+	patterns []string
+}
+
+// NewBotDetector accepts extra names, emails or glob patterns like
+// *@ci.example.com, matched ignoring the case.
+func NewBotDetector(patterns []string) (*BotDetector, error) {
+	d := BotDetector{
+		names:    make(map[string]interface{}),
+		emails:   make(map[string]interface{}),
+		patterns: make([]string, 0, len(patterns)),
+	}
+
+	for _, name := range botNames {
+		d.names[name] = nil
+	}
+	for _, email := range botEmails {
+		d.emails[email] = nil
+	}
+
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+
+		_, err := path.Match(pattern, "")
+		if err != nil {
+			return nil, fmt.Errorf("bad bot pattern: %s, %w", pattern, err)
+		}
+		d.patterns = append(d.patterns, pattern)
+	}
+
+	return &d, nil
+}
+
+func (d *BotDetector) IsBot(identity Identity) bool {
+	name := strings.ToLower(strings.TrimSpace(identity.Name))
+	email := strings.ToLower(strings.TrimSpace(identity.Email))
+
+	emailUser := email
+	if i := strings.Index(email, "@"); i >= 0 {
+		emailUser = email[:i]
+	}
+	if login := githubLogin(email); login != "" {
+		emailUser = login
+	}
+
+	if _, exists := d.names[name]; exists {
+		return true
+	}
+	if _, exists := d.names[emailUser]; exists {
+		return true
+	}
+	if _, exists := d.emails[email]; exists {
+		return true
+	}
+	if botPattern.MatchString(name) || botPattern.MatchString(emailUser) {
+		return true
+	}
+
+	for _, pattern := range d.patterns {
+		for _, value := range []string{name, email} {
+			if matched, _ := path.Match(pattern, value); matched {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,42 @@
+package git
+
+import "testing"
+
+func TestBotDetectorIsBot(t *testing.T) {
+	detector, err := NewBotDetector([]string{"*@ci.example.com", " Release Manager ", ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		identity Identity
+		bot      bool
+	}{
+		{Identity{"dependabot[bot]", "49699333+dependabot[bot]@users.noreply.github.com"}, true},
+		{Identity{"Dependabot", "support@dependabot.com"}, true},
+		{Identity{"GitHub Action", "action@github.com"}, true},
+		{Identity{"github-actions", "41898282+github-actions[bot]@users.noreply.github.com"}, true},
+		{Identity{"Build", "renovate@users.noreply.github.com"}, true},
+		{Identity{"CI Bot", "ci@example.com"}, true},
+		{Identity{"Deploy", "release_bot@example.com"}, true},
+		{Identity{"Nightly", "nightly@CI.example.com"}, true},
+		{Identity{"release manager", "rm@example.com"}, true},
+		{Identity{"Robot Smith", "robot@example.com"}, false},
+		{Identity{"Abbot", "abbot@example.com"}, false},
+		{Identity{"Ann", "ann@example.com"}, false},
+		{Identity{"Ann", "12345+ann@users.noreply.github.com"}, false},
+	}
+
+	for _, test := range tests {
+		if bot := detector.IsBot(test.identity); bot != test.bot {
+			t.Errorf("IsBot(%+v) = %v, want %v", test.identity, bot, test.bot)
+		}
+	}
+}
+
+func TestNewBotDetectorRejectsBadPatterns(t *testing.T) {
+	_, err := NewBotDetector([]string{"[ci"})
+	if err == nil {
+		t.Error("NewBotDetector accepted a bad pattern")
+	}
+}
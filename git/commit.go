@@ -3,22 +3,48 @@ package git
 import "time"
 
 type Commit struct {
-	Id          int
-	Hash        string
-	ParentHash  string
-	Date        time.Time
-	Contributor *Contributor
-	HasGoCode   bool
+	Id            int
+	Hash          string
+	ParentHash    string
+	ParentHashes  []string
+	Date          time.Time
+	CommitterDate time.Time
+	Contributor   *Contributor
+	HasCode       bool
+	Files         []*FileChange
 }
 
-func NewCommit(id int, hash string, parentHash string, date time.Time, contributor *Contributor, hasGoCode bool) *Commit {
+func NewCommit(id int, hash string, parentHashes []string, date time.Time, contributor *Contributor, hasCode bool) *Commit {
 	c := Commit{
-		Id:          id,
-		Hash:        hash,
-		ParentHash:  parentHash,
-		Date:        date,
-		Contributor: contributor,
-		HasGoCode:   hasGoCode,
+		Id:           id,
+		Hash:         hash,
+		ParentHashes: parentHashes,
+		Date:         date,
+		Contributor:  contributor,
+		HasCode:      hasCode,
+	}
+	if len(parentHashes) > 0 {
+		c.ParentHash = parentHashes[0]
 	}
 	return &c
 }
+
+func (c *Commit) IsMerge() bool {
+	return len(c.ParentHashes) > 1
+}
+
+func (c *Commit) LinesAdded() int {
+	added := 0
+	for _, file := range c.Files {
+		added += file.Added
+	}
+	return added
+}
+
+func (c *Commit) LinesDeleted() int {
+	deleted := 0
+	for _, file := range c.Files {
+		deleted += file.Deleted
+	}
+	return deleted
+}
@@ -1,10 +1,12 @@
 package git
 
 type Contributor struct {
-	Id            string
-	Commits       []*Commit
-	firstCommit   *Commit
-	firstGoCommit *Commit
+	Id              string
+	Name            string
+	Bot             bool
+	Commits         []*Commit
+	firstCommit     *Commit
+	firstCodeCommit *Commit
 }
 
 func NewContributor(id string) *Contributor {
@@ -22,8 +24,8 @@ func (c *Contributor) AddCommit(commit *Commit) {
 		c.firstCommit = commit
 	}
 
-	if c.firstGoCommit == nil && commit.HasGoCode {
-		c.firstGoCommit = commit
+	if c.firstCodeCommit == nil && commit.HasCode {
+		c.firstCodeCommit = commit
 	}
 
 	if commit.Date.Before(c.firstCommit.Date) {
@@ -35,8 +37,8 @@ func (c *Contributor) FirstCommit() *Commit {
 	return c.firstCommit
 }
 
-func (c *Contributor) FirstGoCommit() *Commit {
-	return c.firstGoCommit
+func (c *Contributor) FirstCodeCommit() *Commit {
+	return c.firstCodeCommit
 }
 
 func (c *Contributor) IsMainContributor() bool {
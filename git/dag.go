@@ -0,0 +1,226 @@
+package git
+
+import (
+	"container/heap"
+	"fmt"
+)
+
+const (
+	// HistoryFull analyses every commit reachable from HEAD.
+	HistoryFull = "full"
+	// HistoryMainline only analyses the first parent chain of HEAD, commits
+	// from merged branches count when their merge lands on the mainline.
+	HistoryMainline = "mainline"
+)
+
+func ValidateHistory(history string) error {
+	switch history {
+	case "", HistoryFull, HistoryMainline:
+		return nil
+	default:
+		return fmt.Errorf("unknown history: %s, must be one of: %s, %s", history, HistoryFull, HistoryMainline)
+	}
+}
+
+func (g *GitRepo) buildDAG() {
+	g.children = make(map[string][]*Commit, len(g.commits))
+	for _, commit := range g.commits {
+		for _, parent := range g.Parents(commit) {
+			g.children[parent.Hash] = append(g.children[parent.Hash], commit)
+		}
+	}
+
+	// git log starts at HEAD, so it is the only commit without children.
+	g.head = nil
+	for _, commit := range g.commits {
+		if len(g.children[commit.Hash]) == 0 && (g.head == nil || commit.Id > g.head.Id) {
+			g.head = commit
+		}
+	}
+
+	g.mainline = make([]*Commit, 0)
+	for commit := g.head; commit != nil; commit = g.commits[commit.ParentHash] {
+		g.mainline = append(g.mainline, commit)
+	}
+	for i, j := 0, len(g.mainline)-1; i < j; i, j = i+1, j-1 {
+		g.mainline[i], g.mainline[j] = g.mainline[j], g.mainline[i]
+	}
+
+	g.mainlineIndex = make(map[string]int, len(g.mainline))
+	for i, commit := range g.mainline {
+		g.mainlineIndex[commit.Hash] = i
+	}
+
+	// Walking the mainline from the oldest commit, the first mainline commit
+	// that reaches a commit is where it landed.
+	g.landings = make(map[string]*Commit, len(g.commits))
+	for _, mainlineCommit := range g.mainline {
+		g.WalkAncestors(mainlineCommit, func(commit *Commit) bool {
+			if _, landed := g.landings[commit.Hash]; landed {
+				return false
+			}
+			g.landings[commit.Hash] = mainlineCommit
+			return true
+		})
+	}
+}
+
+func (g *GitRepo) Commit(hash string) *Commit {
+	return g.commits[hash]
+}
+
+// Parents returns the parents loaded in the repository, parents missing from
+// a shallow clone are left out.
+func (g *GitRepo) Parents(commit *Commit) []*Commit {
+	parents := make([]*Commit, 0, len(commit.ParentHashes))
+	for _, parentHash := range commit.ParentHashes {
+		parent := g.commits[parentHash]
+		if parent != nil {
+			parents = append(parents, parent)
+		}
+	}
+	return parents
+}
+
+func (g *GitRepo) Children(commit *Commit) []*Commit {
+	return g.children[commit.Hash]
+}
+
+func (g *GitRepo) Head() *Commit {
+	return g.head
+}
+
+// Mainline returns the first parent chain of HEAD, from the oldest commit.
+func (g *GitRepo) Mainline() []*Commit {
+	return g.mainline
+}
+
+func (g *GitRepo) IsMainline(commit *Commit) bool {
+	_, exists := g.mainlineIndex[commit.Hash]
+	return exists
+}
+
+// LandingCommit returns the mainline commit that brought the commit into the
+// mainline history, the commit itself when it is on the mainline.
+func (g *GitRepo) LandingCommit(commit *Commit) *Commit {
+	return g.landings[commit.Hash]
+}
+
+// WalkAncestors visits the commit and its ancestors in breadth first order,
+// each commit once. The parents of a commit are not visited when visit
+// returns false.
+func (g *GitRepo) WalkAncestors(commit *Commit, visit func(commit *Commit) bool) {
+	visited := map[string]interface{}{commit.Hash: nil}
+	queue := []*Commit{commit}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if !visit(current) {
+			continue
+		}
+
+		for _, parent := range g.Parents(current) {
+			if _, exists := visited[parent.Hash]; exists {
+				continue
+			}
+			visited[parent.Hash] = nil
+			queue = append(queue, parent)
+		}
+	}
+}
+
+func (g *GitRepo) IsAncestor(ancestor *Commit, commit *Commit) bool {
+	found := false
+	g.WalkAncestors(commit, func(current *Commit) bool {
+		if current == ancestor {
+			found = true
+		}
+		// Ancestors are never newer than their descendants in git log order.
+		return !found && current.Id >= ancestor.Id
+	})
+	return found
+}
+
+// forkPoint returns the newest mainline commit the commit is based on.
+func (g *GitRepo) forkPoint(commit *Commit) *Commit {
+	var forkPoint *Commit
+	g.WalkAncestors(commit, func(current *Commit) bool {
+		if !g.IsMainline(current) {
+			return true
+		}
+		if forkPoint == nil || g.mainlineIndex[current.Hash] > g.mainlineIndex[forkPoint.Hash] {
+			forkPoint = current
+		}
+		return false
+	})
+	return forkPoint
+}
+
+// CommitsBetween returns the commits reachable from the commit but not from
+// since, like git log since..commit, all the ancestors when since is nil.
+// As in git rev-list, both sides are walked together from the newest commit
+// and the ancestors of since are marked as excluded, so the walk stops once
+// only excluded commits are left, instead of visiting all of their history.
+func (g *GitRepo) CommitsBetween(since *Commit, commit *Commit) []*Commit {
+	excluded := make(map[string]bool)
+	queue := commitQueue{}
+	included := 0
+
+	push := func(current *Commit, exclude bool) {
+		wasExcluded, queued := excluded[current.Hash]
+		if !queued {
+			excluded[current.Hash] = exclude
+			heap.Push(&queue, current)
+			if !exclude {
+				included++
+			}
+			return
+		}
+		if exclude && !wasExcluded {
+			excluded[current.Hash] = true
+			included--
+		}
+	}
+
+	push(commit, false)
+	if since != nil {
+		push(since, true)
+	}
+
+	commits := make([]*Commit, 0)
+	for included > 0 {
+		// Commit ids are a topological order, every child of the commit was
+		// already visited and told whether it is excluded.
+		current := heap.Pop(&queue).(*Commit)
+		exclude := excluded[current.Hash]
+		if !exclude {
+			included--
+			commits = append(commits, current)
+		}
+
+		for _, parent := range g.Parents(current) {
+			push(parent, exclude)
+		}
+	}
+	return commits
+}
+
+// commitQueue pops the newest commit first.
+type commitQueue []*Commit
+
+func (q commitQueue) Len() int           { return len(q) }
+func (q commitQueue) Less(i, j int) bool { return q[i].Id > q[j].Id }
+func (q commitQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *commitQueue) Push(x interface{}) {
+	*q = append(*q, x.(*Commit))
+}
+
+func (q *commitQueue) Pop() interface{} {
+	old := *q
+	commit := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return commit
+}
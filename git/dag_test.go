@@ -0,0 +1,57 @@
+package git
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestRepo loads commits given as "hash:parent,parent" from the oldest.
+func newTestRepo(commits ...string) *GitRepo {
+	g := GitRepo{commits: make(map[string]*Commit)}
+	for i, commit := range commits {
+		parts := strings.SplitN(commit, ":", 2)
+		parents := make([]string, 0)
+		if len(parts) == 2 && parts[1] != "" {
+			parents = strings.Split(parts[1], ",")
+		}
+		g.commits[parts[0]] = NewCommit(i, parts[0], parents, time.Time{}, nil, true)
+	}
+	return &g
+}
+
+func hashes(commits []*Commit) string {
+	result := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		result = append(result, commit.Hash)
+	}
+	sort.Strings(result)
+	return strings.Join(result, " ")
+}
+
+func TestCommitsBetween(t *testing.T) {
+	g := newTestRepo("a", "b:a", "c:b", "d:b", "e:d,c", "f:e", "g:a")
+
+	tests := []struct {
+		since  string
+		commit string
+		want   string
+	}{
+		{"", "f", "a b c d e f"},
+		{"d", "f", "c e f"},
+		{"c", "f", "d e f"},
+		{"b", "c", "c"},
+		{"f", "d", ""},
+		{"f", "f", ""},
+		{"g", "f", "b c d e f"},
+		{"f", "g", "g"},
+	}
+
+	for _, test := range tests {
+		since := g.Commit(test.since)
+		if got := hashes(g.CommitsBetween(since, g.Commit(test.commit))); got != test.want {
+			t.Errorf("CommitsBetween(%s, %s) = %q, want %q", test.since, test.commit, got, test.want)
+		}
+	}
+}
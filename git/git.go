@@ -1,14 +1,14 @@
 package git
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"path"
+	"path/filepath"
 	"sort"
-	"strconv"
 	"strings"
-	"time"
 
 	"github.com/diegocsandrim/sonarminer/cmd"
 )
@@ -23,156 +23,269 @@ func init() {
 	}
 }
 
+type LoadOptions struct {
+	Languages *LanguageSet
+	History   string
+	// AliasFile is a file in .mailmap format applied on top of the
+	// repository .mailmap.
+	AliasFile       string
+	MergeIdentities bool
+	// Bots are names, emails or glob patterns of automation accounts, on
+	// top of the built-in ones.
+	Bots        []string
+	IncludeBots bool
+	Newcomers   NewcomerPolicy
+	Attraction  AttractionModel
+}
+
 type GitRepo struct {
-	namespace    string
-	project      string
-	cmdFactory   *cmd.CmdFactory
-	commits      map[string]*Commit
-	contributors map[string]*Contributor
+	remote         *Remote
+	worktreeSource string
+	worktreeDir    string
+	cmdFactory     *cmd.CmdFactory
+	history        string
+	newcomers      NewcomerPolicy
+	attraction     AttractionModel
+	tags           []*Tag
+	commits        map[string]*Commit
+	contributors   map[string]*Contributor
+	children       map[string][]*Commit
+	head           *Commit
+	mainline       []*Commit
+	mainlineIndex  map[string]int
+	landings       map[string]*Commit
+	identityMerges []*IdentityMerge
 }
 
-func NewGitRepo(namespace string, project string) *GitRepo {
+func NewGitRepo(remote *Remote) *GitRepo {
 	g := GitRepo{
-		namespace: namespace,
-		project:   project,
+		remote: remote,
 	}
-	g.cmdFactory = cmd.NewCmdFactory(g.ProjectDir())
+	g.cmdFactory = newGitCmdFactory(g.ProjectDir())
 
 	return &g
 }
 
-func (g *GitRepo) ForceClone() error {
-	_, err := cmd.NewCmdFactory("/").ExecF("mkdir -p %s", g.namespaceDir())
+// newGitCmdFactory runs git without prompts, a repository asking for
+// credentials fails instead of blocking the analysis.
+func newGitCmdFactory(dir string) *cmd.CmdFactory {
+	cmdFactory := cmd.NewCmdFactory(dir)
+	cmdFactory.Env = []string{"GIT_TERMINAL_PROMPT=0"}
+	return cmdFactory
+}
+
+func (g *GitRepo) git(ctx context.Context, args ...string) (string, error) {
+	output, err := g.cmdFactory.Run(ctx, "git", args...)
+	if err != nil {
+		return "", err
+	}
+	return output.Stdout, nil
+}
+
+func (g *GitRepo) ForceClone(ctx context.Context) error {
+	// The project directory is removed, it must be a cache directory.
+	relativeDir, err := filepath.Rel(GitBaseDir, filepath.Clean(g.ProjectDir()))
+	if err != nil || relativeDir == "." || relativeDir == ".." || strings.HasPrefix(relativeDir, "../") {
+		return fmt.Errorf("repository directory '%s' is not in the cache directory '%s'", g.ProjectDir(), GitBaseDir)
+	}
+
+	err = os.MkdirAll(g.parentDir(), 0775)
 	if err != nil {
 		return err
 	}
 
-	_, err = cmd.NewCmdFactory("/").ExecF("rm -rf %s", g.ProjectDir())
+	err = os.RemoveAll(g.ProjectDir())
 	if err != nil {
 		return err
 	}
 
-	_, err = cmd.NewCmdFactory(g.namespaceDir()).ExecF("git clone https://github.com/%s/%s.git", g.namespace, g.project)
+	_, err = newGitCmdFactory(g.parentDir()).Run(ctx, "git", "clone", "--", g.remote.URL, g.ProjectDir())
 	return err
 }
 
-func (g *GitRepo) Clone() error {
-	clearCommand := `git reset --hard HEAD
-git clean -f -d
-remote_name=$(git remote | egrep -o '(upstream|origin)' | tail -1)
-default_branch=$(git remote show $remote_name | awk '/HEAD branch/ {print $NF}')
-git checkout $default_branch
-git fetch origin $default_branch
-git reset --hard origin/$default_branch
-git pull`
-
-	_, err := cmd.NewCmdFactory(g.ProjectDir()).ExecF(clearCommand)
+func (g *GitRepo) Clone(ctx context.Context) error {
+	if g.worktreeDir != "" {
+		return g.addWorktree(ctx)
+	}
+
+	_, err := os.Stat(path.Join(g.ProjectDir(), ".git"))
 	if err != nil {
 		log.Printf("project is not in local cache, cloning from remote...")
-		return g.ForceClone()
+		return g.ForceClone(ctx)
+	}
+
+	err = g.update(ctx)
+	if ctx.Err() != nil {
+		return err
+	}
+	if err != nil {
+		log.Printf("fail to update the cached clone, cloning from remote again: %s", err.Error())
+		return g.ForceClone(ctx)
 	}
 	return nil
 }
 
-func (g *GitRepo) hasGoCode(fileNames []string) (bool, error) {
+// update resets the cached clone to the remote default branch.
+func (g *GitRepo) update(ctx context.Context) error {
+	_, err := g.git(ctx, "reset", "--hard", "HEAD")
+	if err != nil {
+		return err
+	}
+
+	_, err = g.git(ctx, "clean", "-f", "-d")
+	if err != nil {
+		return err
+	}
+
+	symref, err := g.git(ctx, "ls-remote", "--symref", "origin", "HEAD")
+	if err != nil {
+		return err
+	}
+
+	defaultBranch := ""
+	for _, line := range strings.Split(symref, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 3 && fields[0] == "ref:" && fields[2] == "HEAD" {
+			defaultBranch = strings.TrimPrefix(fields[1], "refs/heads/")
+		}
+	}
+	if defaultBranch == "" {
+		return fmt.Errorf("could not find the default branch of origin")
+	}
+
+	_, err = g.git(ctx, "fetch", "origin", defaultBranch)
+	if err != nil {
+		return err
+	}
+
+	_, err = g.git(ctx, "checkout", "--force", "-B", defaultBranch, "origin/"+defaultBranch)
+	return err
+}
+
+func (g *GitRepo) hasCode(fileNames []string, languages *LanguageSet) bool {
 	for _, fileName := range fileNames {
-		if strings.HasSuffix(fileName, ".go") {
-			return true, nil
+		if languages.IsCode(fileName) {
+			return true
 		}
 	}
 
-	return false, nil
+	return false
 }
 
-func (g *GitRepo) LoadCommits() error {
-	g.commits = make(map[string]*Commit)
-	g.contributors = make(map[string]*Contributor)
+func (g *GitRepo) LoadCommits(ctx context.Context, options LoadOptions) error {
+	languages := options.Languages
+	if languages == nil {
+		var err error
+		languages, err = NewLanguageSet(DefaultLanguages)
+		if err != nil {
+			return err
+		}
+	}
 
-	commitLinePrefix := "commit:"
-	commitsLog, err := g.cmdFactory.ExecF("git log --format='%s%%H/////%%at/////%%aE/////%%P' --reverse --name-only", commitLinePrefix)
+	err := ValidateHistory(options.History)
 	if err != nil {
 		return err
 	}
 
-	commitId := 0
-	commitsLogLines := strings.Split(commitsLog, "\n")
+	bots, err := NewBotDetector(options.Bots)
+	if err != nil {
+		return err
+	}
+	g.history = options.History
 
-	for line := 0; line < len(commitsLogLines); line++ {
-		commitLog := commitsLogLines[line]
+	err = options.Newcomers.Validate()
+	if err != nil {
+		return err
+	}
+	g.newcomers = options.Newcomers
 
-		if commitLog == "" {
-			continue
-		}
-		splittedLogLine := strings.Split(commitLog, "/////")
-		if len(splittedLogLine) != 4 {
-			log.Panicf("commits log line is in a bad format: '%s'", commitLog)
-		}
+	err = options.Attraction.Validate()
+	if err != nil {
+		return err
+	}
+	g.attraction = options.Attraction
 
-		commitHash := splittedLogLine[0][len(commitLinePrefix):]
-		commitTimestampString := splittedLogLine[1]
-		contributorId := splittedLogLine[2]
-		parentCommitHashs := strings.Split(splittedLogLine[3], " ")
+	g.commits = make(map[string]*Commit)
+	g.contributors = make(map[string]*Contributor)
 
-		commitFileNames := make([]string, 0)
+	args := logArgs
+	if options.AliasFile != "" {
+		aliasFile, err := filepath.Abs(options.AliasFile)
+		if err != nil {
+			return err
+		}
+		// git ignores a missing mailmap file, a typo would go unnoticed.
+		_, err = os.Stat(aliasFile)
+		if err != nil {
+			return fmt.Errorf("fail to read the alias file: %w", err)
+		}
+		args = append([]string{"-c", "mailmap.file=" + aliasFile}, logArgs...)
+	}
 
-		for {
-			if line+1 == len(commitsLogLines) {
-				break
-			}
-			nextLine := commitsLogLines[line+1]
-			if nextLine == "" {
-				line++
-				continue
-			}
+	commitsLog, err := g.git(ctx, args...)
+	if err != nil {
+		return err
+	}
 
-			if strings.HasPrefix(nextLine, commitLinePrefix) {
-				break
-			}
+	entries, err := parseLog(commitsLog)
+	if err != nil {
+		return err
+	}
 
-			if strings.HasPrefix(nextLine, "warning: ") {
-				line++
-				continue
-			}
+	resolver := newIdentityResolver()
+	for commitId, entry := range entries {
+		resolver.add(entry.author(), commitId)
+	}
+	if options.MergeIdentities {
+		resolver.mergeHeuristically()
+	}
 
-			commitFileNames = append(commitFileNames, nextLine)
-			line++
+	for commitId, entry := range entries {
+		fileNames := make([]string, 0, len(entry.files))
+		for _, file := range entry.files {
+			fileNames = append(fileNames, file.Path)
 		}
 
-		hasGoCode, err := g.hasGoCode(commitFileNames)
-		if err != nil {
-			return err
-		}
+		contributorId := resolver.contributorId(entry.author())
 
 		contributor, contributorExists := g.contributors[contributorId]
 		if !contributorExists {
 			contributor = NewContributor(contributorId)
+			contributor.Name = resolver.contributorName(entry.author())
 			g.contributors[contributor.Id] = contributor
 		}
-
-		commitTimestampInt, err := strconv.ParseInt(commitTimestampString, 10, 64)
-		if err != nil {
-			log.Panicf("commits log timestamp is in a bad format: '%s'", commitTimestampString)
+		if !options.IncludeBots && bots.IsBot(entry.author()) {
+			contributor.Bot = true
 		}
 
-		commitTimestamp := time.Unix(commitTimestampInt, 0)
-
-		commit := NewCommit(commitId, commitHash, parentCommitHashs[0], commitTimestamp, contributor, hasGoCode)
-		commitId++
+		commit := NewCommit(commitId, entry.hash, entry.parentHashes, entry.authorDate, contributor, g.hasCode(fileNames, languages))
+		commit.CommitterDate = entry.committerDate
+		commit.Files = entry.files
 
 		contributor.AddCommit(commit)
 
 		g.commits[commit.Hash] = commit
-
 	}
-	return nil
+
+	g.identityMerges = resolver.merges(entries)
+	g.buildDAG()
+
+	return g.loadTags(ctx)
+}
+
+func (g *GitRepo) Remote() *Remote {
+	return g.remote
 }
 
 func (g *GitRepo) ProjectDir() string {
-	return fmt.Sprintf("%s/%s/%s", GitBaseDir, g.namespace, g.project)
+	if g.worktreeDir != "" {
+		return g.worktreeDir
+	}
+	return path.Join(GitBaseDir, g.remote.CacheDir())
 }
 
-func (g *GitRepo) namespaceDir() string {
-	return fmt.Sprintf("%s/%s", GitBaseDir, g.namespace)
+func (g *GitRepo) parentDir() string {
+	return path.Dir(g.ProjectDir())
 }
 
 func (g *GitRepo) Contributors() []*Contributor {
@@ -187,8 +300,12 @@ func (g *GitRepo) Contributors() []*Contributor {
 func (g *GitRepo) Commits() []*Commit {
 	commits := make([]*Commit, 0, len(g.commits))
 
-	for _, commit := range g.commits {
-		commits = append(commits, commit)
+	if g.history == HistoryMainline {
+		commits = append(commits, g.mainline...)
+	} else {
+		for _, commit := range g.commits {
+			commits = append(commits, commit)
+		}
 	}
 
 	sort.Slice(commits, func(i, j int) bool {
@@ -198,28 +315,52 @@ func (g *GitRepo) Commits() []*Commit {
 	return commits
 }
 
+// ContributorAttractorCommits groups the newcomers by the commit they are
+// attributed to by the attraction model.
 func (g *GitRepo) ContributorAttractorCommits() []*ContributorAttractorCommit {
+	if g.attraction.Mode == "" || g.attraction.Mode == AttractionParent {
+		return g.parentAttractorCommits()
+	}
+
+	contributorAttractorCommitsByCommitHash := make(map[string]*ContributorAttractorCommit)
+	contributorAttractorCommits := make([]*ContributorAttractorCommit, 0)
+	for _, window := range g.AttractionWindows() {
+		if len(window.Contributors) == 0 {
+			continue
+		}
+
+		contributorAttractorCommit, exists := contributorAttractorCommitsByCommitHash[window.Commit.Hash]
+		if !exists {
+			contributorAttractorCommit = NewContributorAttractorCommit(window.Commit)
+			contributorAttractorCommitsByCommitHash[window.Commit.Hash] = contributorAttractorCommit
+			contributorAttractorCommits = append(contributorAttractorCommits, contributorAttractorCommit)
+		}
+
+		for _, contributor := range window.Contributors {
+			contributorAttractorCommit.AddAttractedContributor(contributor)
+		}
+	}
+
+	return contributorAttractorCommits
+}
+
+func (g *GitRepo) parentAttractorCommits() []*ContributorAttractorCommit {
 	contributorAttractorCommitsByCommitHash := make(map[string]*ContributorAttractorCommit)
 
 	for _, contributor := range g.contributors {
-		contributorFirstGoCommit := contributor.FirstGoCommit()
-		if contributorFirstGoCommit == nil {
+		if !g.CountsAsContributor(contributor) {
 			continue
 		}
-		if contributorFirstGoCommit.ParentHash == "" {
+
+		entranceCommit := g.newcomers.EntranceCommit(contributor)
+		if entranceCommit.ParentHash == "" {
 			continue
 		}
 
-		parentCommit := g.commits[contributorFirstGoCommit.ParentHash]
+		parentCommit := g.attractorCommit(contributor, entranceCommit)
 		if parentCommit == nil {
-			log.Printf("Missing required parent commit! parent hash: %s", contributorFirstGoCommit.ParentHash)
-		}
-
-		if !contributor.IsMainContributor() {
-			//Find a parent commit that is authored by other contributor
-			for parentCommit.Contributor == contributor {
-				parentCommit = g.commits[parentCommit.ParentHash]
-			}
+			log.Printf("Missing required parent commit! contributor: %s, entrance commit: %s", contributor.Id, entranceCommit.Hash)
+			continue
 		}
 
 		contributorAttractorCommit, exists := contributorAttractorCommitsByCommitHash[parentCommit.Hash]
@@ -239,33 +380,84 @@ func (g *GitRepo) ContributorAttractorCommits() []*ContributorAttractorCommit {
 	return contributorAttractorCommits
 }
 
+func (g *GitRepo) Newcomers() NewcomerPolicy {
+	return g.newcomers
+}
+
+// CountsAsContributor tells if the contributor is counted in the analyses, bots
+// and contributors below the newcomer thresholds are not.
+func (g *GitRepo) CountsAsContributor(contributor *Contributor) bool {
+	return !contributor.Bot && g.newcomers.Counts(contributor)
+}
+
+// attractorCommit returns the commit the contributor found when starting to
+// contribute, skipping back the commits of the contributor. In mainline
+// history it is the mainline commit their branch was based on.
+func (g *GitRepo) attractorCommit(contributor *Contributor, entranceCommit *Commit) *Commit {
+	attractor := g.commits[entranceCommit.ParentHash]
+	if g.history == HistoryMainline && !g.IsMainline(entranceCommit) {
+		attractor = g.forkPoint(entranceCommit)
+	}
+
+	if !contributor.IsMainContributor() {
+		for attractor != nil && attractor.Contributor == contributor {
+			attractor = g.commits[attractor.ParentHash]
+		}
+	}
+
+	return attractor
+}
+
+// CodeCommitsByPeriod groups the commits considered by the newcomer policy by
+// period. Only code commits are landing commits, periods without code changes
+// are left out.
 func (g *GitRepo) CodeCommitsByPeriod(months int) []*MonthCommits {
 	monthlyCommitsMap := map[YearPeriod]*MonthCommits{}
+	landingCommits := map[YearPeriod]map[string]interface{}{}
 
 	for _, commit := range g.commits {
-		if !commit.HasGoCode {
+		if !g.newcomers.Considers(commit) {
 			continue
 		}
 
+		landingCommit := commit
+		if g.history == HistoryMainline {
+			landingCommit = g.LandingCommit(commit)
+			if landingCommit == nil {
+				continue
+			}
+		}
+
 		monthYear := YearPeriod{
-			Period: int(commit.Date.Month()-1) / months,
-			Year:   commit.Date.Year(),
+			Period: int(landingCommit.Date.Month()-1) / months,
+			Year:   landingCommit.Date.Year(),
 		}
 		monthCommits, exists := monthlyCommitsMap[monthYear]
 		if !exists {
 			monthCommits = &MonthCommits{
-				Month:   monthYear,
-				Commits: []*Commit{},
+				Month:          monthYear,
+				Commits:        []*Commit{},
+				LandingCommits: []*Commit{},
 			}
 			monthlyCommitsMap[monthYear] = monthCommits
+			landingCommits[monthYear] = map[string]interface{}{}
 		}
 
 		monthCommits.Commits = append(monthCommits.Commits, commit)
+		if !commit.HasCode {
+			continue
+		}
+		if _, exists := landingCommits[monthYear][landingCommit.Hash]; !exists {
+			landingCommits[monthYear][landingCommit.Hash] = nil
+			monthCommits.LandingCommits = append(monthCommits.LandingCommits, landingCommit)
+		}
 	}
 
 	monthlyCommits := make([]*MonthCommits, 0, len(monthlyCommitsMap))
 	for _, monthCommits := range monthlyCommitsMap {
-		monthlyCommits = append(monthlyCommits, monthCommits)
+		if len(monthCommits.LandingCommits) > 0 {
+			monthlyCommits = append(monthlyCommits, monthCommits)
+		}
 	}
 
 	sort.Slice(monthlyCommits, func(i, j int) bool {
@@ -280,7 +472,23 @@ func (g *GitRepo) CodeCommitsByPeriod(months int) []*MonthCommits {
 	return monthlyCommits
 }
 
-func (g *GitRepo) Checkout(ref string) error {
-	_, err := g.cmdFactory.ExecF("git checkout --force %s", ref)
+func (g *GitRepo) Checkout(ctx context.Context, ref string) error {
+	if strings.HasPrefix(ref, "-") {
+		return fmt.Errorf("invalid git ref: '%s'", ref)
+	}
+
+	_, err := g.git(ctx, "checkout", "--force", ref)
 	return err
 }
+
+func (g *GitRepo) Close() error {
+	if g.worktreeDir != "" {
+		return g.removeWorktree()
+	}
+	return nil
+}
+
+// IdentityMerges lists the author identities counted as another contributor.
+func (g *GitRepo) IdentityMerges() []*IdentityMerge {
+	return g.identityMerges
+}
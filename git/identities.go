@@ -0,0 +1,239 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	MergeReasonMailmap       = "mailmap"
+	MergeReasonSameName      = "same name"
+	MergeReasonGitHubNoreply = "github noreply"
+	MergeReasonEmailCase     = "email case"
+
+	githubNoreplyDomain = "@users.noreply.github.com"
+)
+
+type Identity struct {
+	Name  string
+	Email string
+}
+
+func (i Identity) String() string {
+	return fmt.Sprintf("%s <%s>", i.Name, i.Email)
+}
+
+// key identifies the contributor, commits without author email are still
+// counted by the author name. Emails are case insensitive in practice.
+func (i Identity) key() string {
+	if i.Email == "" {
+		return i.Name
+	}
+	return strings.ToLower(i.Email)
+}
+
+// IdentityMerge is an author identity counted as part of another contributor.
+type IdentityMerge struct {
+	Contributor string
+	Identity    Identity
+	Commits     int
+	Reason      string
+}
+
+type identityStats struct {
+	identity    Identity
+	commits     int
+	firstCommit int
+}
+
+// identityResolver merges the author identities, already resolved by
+// .mailmap, that heuristically belong to the same person.
+type identityResolver struct {
+	identities map[string]*identityStats
+	parents    map[string]string
+	reasons    map[string]string
+}
+
+func newIdentityResolver() *identityResolver {
+	r := identityResolver{
+		identities: make(map[string]*identityStats),
+		parents:    make(map[string]string),
+		reasons:    make(map[string]string),
+	}
+	return &r
+}
+
+func (r *identityResolver) add(identity Identity, commitId int) {
+	stats, exists := r.identities[identity.key()]
+	if !exists {
+		stats = &identityStats{identity: identity, firstCommit: commitId}
+		r.identities[identity.key()] = stats
+		r.parents[identity.key()] = identity.key()
+	}
+	stats.commits++
+}
+
+func (r *identityResolver) find(key string) string {
+	for r.parents[key] != key {
+		r.parents[key] = r.parents[r.parents[key]]
+		key = r.parents[key]
+	}
+	return key
+}
+
+func (r *identityResolver) union(key1 string, key2 string, reason string) {
+	root1, root2 := r.find(key1), r.find(key2)
+	if root1 == root2 {
+		return
+	}
+
+	if r.preferred(root2, root1) {
+		root1, root2 = root2, root1
+	}
+	r.parents[root2] = root1
+
+	for _, key := range []string{key1, key2} {
+		if r.reasons[key] == "" {
+			r.reasons[key] = reason
+		}
+	}
+}
+
+// preferred chooses the identity that names the merged contributor: a real
+// address over a GitHub noreply one, then the one with more commits.
+func (r *identityResolver) preferred(key1 string, key2 string) bool {
+	stats1, stats2 := r.identities[key1], r.identities[key2]
+	noreply1, noreply2 := githubLogin(stats1.identity.Email) != "", githubLogin(stats2.identity.Email) != ""
+	if noreply1 != noreply2 {
+		return noreply2
+	}
+	if stats1.commits != stats2.commits {
+		return stats1.commits > stats2.commits
+	}
+	return stats1.firstCommit < stats2.firstCommit
+}
+
+// mergeHeuristically merges identities with the same full name, and GitHub
+// noreply addresses with the identities using the same login as name or as
+// email user.
+func (r *identityResolver) mergeHeuristically() {
+	keys := make([]string, 0, len(r.identities))
+	for key := range r.identities {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	byName := make(map[string]string)
+	byLogin := make(map[string][]string)
+	for _, key := range keys {
+		identity := r.identities[key].identity
+
+		name := normalizeName(identity.Name)
+		if len(strings.Fields(name)) > 1 {
+			if other, exists := byName[name]; exists {
+				r.union(other, key, MergeReasonSameName)
+			} else {
+				byName[name] = key
+			}
+		}
+
+		for _, login := range loginCandidates(identity) {
+			byLogin[login] = append(byLogin[login], key)
+		}
+	}
+
+	for _, key := range keys {
+		login := githubLogin(r.identities[key].identity.Email)
+		if login == "" {
+			continue
+		}
+		for _, other := range byLogin[login] {
+			r.union(key, other, MergeReasonGitHubNoreply)
+		}
+	}
+}
+
+func (r *identityResolver) contributorId(identity Identity) string {
+	return r.identities[r.find(identity.key())].identity.key()
+}
+
+func (r *identityResolver) contributorName(identity Identity) string {
+	return r.identities[r.find(identity.key())].identity.Name
+}
+
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// githubLogin returns the login of a GitHub noreply address, in the format
+// login@users.noreply.github.com or id+login@users.noreply.github.com.
+func githubLogin(email string) string {
+	email = strings.ToLower(email)
+	if !strings.HasSuffix(email, githubNoreplyDomain) {
+		return ""
+	}
+
+	login := strings.TrimSuffix(email, githubNoreplyDomain)
+	if i := strings.Index(login, "+"); i >= 0 {
+		login = login[i+1:]
+	}
+	return login
+}
+
+func loginCandidates(identity Identity) []string {
+	candidates := make([]string, 0, 2)
+
+	name := normalizeName(identity.Name)
+	if name != "" && !strings.Contains(name, " ") {
+		candidates = append(candidates, name)
+	}
+
+	if i := strings.Index(identity.Email, "@"); i > 0 && githubLogin(identity.Email) == "" {
+		candidates = append(candidates, strings.ToLower(identity.Email[:i]))
+	}
+
+	return candidates
+}
+
+// merges lists every author identity, as written in the commits, counted as
+// another contributor, either by the mailmap, by the heuristics or by an
+// email only differing in case.
+func (r *identityResolver) merges(entries []*logEntry) []*IdentityMerge {
+	merges := make(map[Identity]*IdentityMerge)
+
+	for _, entry := range entries {
+		raw := entry.rawAuthor()
+		mapped := entry.author()
+		contributor := r.identities[r.find(mapped.key())].identity
+		contributorId := contributor.key()
+		if raw.key() == contributorId && raw.Email == contributor.Email {
+			continue
+		}
+
+		merge, exists := merges[raw]
+		if !exists {
+			reason := MergeReasonMailmap
+			if raw.key() == contributorId {
+				reason = MergeReasonEmailCase
+			} else if raw.key() == mapped.key() {
+				reason = r.reasons[mapped.key()]
+			}
+			merge = &IdentityMerge{Contributor: contributorId, Identity: raw, Reason: reason}
+			merges[raw] = merge
+		}
+		merge.Commits++
+	}
+
+	result := make([]*IdentityMerge, 0, len(merges))
+	for _, merge := range merges {
+		result = append(result, merge)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Contributor != result[j].Contributor {
+			return result[i].Contributor < result[j].Contributor
+		}
+		return result[i].Identity.String() < result[j].Identity.String()
+	})
+	return result
+}
@@ -0,0 +1,94 @@
+package git
+
+import "testing"
+
+func resolve(identities []Identity, merge bool) *identityResolver {
+	resolver := newIdentityResolver()
+	for i, identity := range identities {
+		resolver.add(identity, i)
+	}
+	if merge {
+		resolver.mergeHeuristically()
+	}
+	return resolver
+}
+
+func TestIdentityResolver(t *testing.T) {
+	identities := []Identity{
+		{Name: "Jane Doe", Email: "jane@corp.com"},
+		{Name: "Jane Doe", Email: "Jane@Corp.com"},
+		{Name: "jane  doe", Email: "jane@gmail.com"},
+		{Name: "jdoe", Email: "123+jdoe@users.noreply.github.com"},
+		{Name: "John Smith", Email: "jdoe@corp.com"},
+		{Name: "Bob", Email: "bob@x.com"},
+		{Name: "Bob", Email: "bob@home.org"},
+		{Name: "No Email", Email: ""},
+	}
+
+	tests := []struct {
+		identity Identity
+		merge    bool
+		want     string
+	}{
+		{identities[1], false, "jane@corp.com"},
+		{identities[2], false, "jane@gmail.com"},
+		{identities[2], true, "jane@corp.com"},
+		{identities[3], false, "123+jdoe@users.noreply.github.com"},
+		{identities[3], true, "jdoe@corp.com"},
+		{identities[6], true, "bob@home.org"},
+		{identities[7], true, "No Email"},
+	}
+
+	for _, test := range tests {
+		resolver := resolve(identities, test.merge)
+		if got := resolver.contributorId(test.identity); got != test.want {
+			t.Errorf("contributorId(%s) with merge=%t = %q, want %q", test.identity, test.merge, got, test.want)
+		}
+	}
+}
+
+func TestIdentityResolverPrefersRealEmails(t *testing.T) {
+	noreply := Identity{Name: "ann", Email: "ann@users.noreply.github.com"}
+	real := Identity{Name: "ann", Email: "ann@example.com"}
+
+	resolver := newIdentityResolver()
+	resolver.add(noreply, 0)
+	resolver.add(noreply, 1)
+	resolver.add(real, 2)
+	resolver.mergeHeuristically()
+
+	if got := resolver.contributorId(noreply); got != "ann@example.com" {
+		t.Errorf("contributorId(%s) = %q, want the real email", noreply, got)
+	}
+}
+
+func TestIdentityMerges(t *testing.T) {
+	entries := []*logEntry{
+		{authorName: "Ann", authorEmail: "ann@new.org", rawAuthorName: "Ann", rawAuthorEmail: "ann@old.org"},
+		{authorName: "Ann", authorEmail: "ann@new.org", rawAuthorName: "Ann", rawAuthorEmail: "ann@new.org"},
+		{authorName: "Jane Doe", authorEmail: "jane@corp.com", rawAuthorName: "Jane Doe", rawAuthorEmail: "jane@corp.com"},
+		{authorName: "Jane Doe", authorEmail: "jane@corp.com", rawAuthorName: "Jane Doe", rawAuthorEmail: "jane@corp.com"},
+		{authorName: "Jane Doe", authorEmail: "jane@gmail.com", rawAuthorName: "Jane Doe", rawAuthorEmail: "jane@gmail.com"},
+		{authorName: "Jane Doe", authorEmail: "Jane@Corp.com", rawAuthorName: "Jane Doe", rawAuthorEmail: "Jane@Corp.com"},
+	}
+
+	resolver := newIdentityResolver()
+	for i, entry := range entries {
+		resolver.add(entry.author(), i)
+	}
+	resolver.mergeHeuristically()
+
+	merges := resolver.merges(entries)
+	if len(merges) != 3 {
+		t.Fatalf("merges() = %d merges, want 3", len(merges))
+	}
+	if merges[0].Contributor != "ann@new.org" || merges[0].Identity.Email != "ann@old.org" || merges[0].Reason != MergeReasonMailmap {
+		t.Errorf("merges()[0] = %+v, want ann@old.org merged by mailmap", merges[0])
+	}
+	if merges[1].Contributor != "jane@corp.com" || merges[1].Identity.Email != "Jane@Corp.com" || merges[1].Reason != MergeReasonEmailCase {
+		t.Errorf("merges()[1] = %+v, want Jane@Corp.com merged by email case", merges[1])
+	}
+	if merges[2].Contributor != "jane@corp.com" || merges[2].Identity.Email != "jane@gmail.com" || merges[2].Reason != MergeReasonSameName {
+		t.Errorf("merges()[2] = %+v, want jane@gmail.com merged by same name", merges[2])
+	}
+}
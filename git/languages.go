@@ -0,0 +1,127 @@
+package git
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+var DefaultLanguages = []string{"go"}
+
+var languageExtensions = map[string][]string{
+	"c":          {".c", ".h"},
+	"cpp":        {".cpp", ".cc", ".cxx", ".c++", ".hpp", ".hh", ".hxx", ".h++"},
+	"csharp":     {".cs"},
+	"go":         {".go"},
+	"java":       {".java"},
+	"javascript": {".js", ".jsx", ".mjs", ".cjs"},
+	"kotlin":     {".kt", ".kts"},
+	"php":        {".php", ".php3", ".php4", ".php5", ".phtml"},
+	"python":     {".py"},
+	"ruby":       {".rb"},
+	"rust":       {".rs"},
+	"scala":      {".scala"},
+	"swift":      {".swift"},
+	"typescript": {".ts", ".tsx"},
+	"vb":         {".vb"},
+}
+
+var languageAliases = map[string]string{
+	"c++":    "cpp",
+	"c#":     "csharp",
+	"golang": "go",
+	"js":     "javascript",
+	"py":     "python",
+	"ts":     "typescript",
+}
+
+var vendoredDirs = []string{"vendor/", "node_modules/", "third_party/", "bower_components/"}
+
+var generatedSuffixes = []string{".pb.go", "_gen.go", ".min.js", "_pb2.py", ".designer.cs", ".g.dart"}
+
+type LanguageSet struct {
+	names      []string
+	extensions map[string]interface{}
+}
+
+func NewLanguageSet(languages []string) (*LanguageSet, error) {
+	l := LanguageSet{
+		names:      make([]string, 0, len(languages)),
+		extensions: make(map[string]interface{}),
+	}
+
+	for _, language := range languages {
+		language = strings.ToLower(strings.TrimSpace(language))
+		if language == "" {
+			continue
+		}
+
+		if strings.HasPrefix(language, ".") {
+			l.extensions[language] = nil
+			l.names = append(l.names, language)
+			continue
+		}
+
+		if alias, exists := languageAliases[language]; exists {
+			language = alias
+		}
+
+		extensions, exists := languageExtensions[language]
+		if !exists {
+			return nil, fmt.Errorf("unknown language: %s, must be one of: %s, or a file extension like .proto", language, strings.Join(Languages(), ", "))
+		}
+
+		for _, extension := range extensions {
+			l.extensions[extension] = nil
+		}
+		l.names = append(l.names, language)
+	}
+
+	if len(l.extensions) == 0 {
+		return nil, fmt.Errorf("at least one language must be provided")
+	}
+
+	return &l, nil
+}
+
+func (l *LanguageSet) IsCode(fileName string) bool {
+	fileName = strings.ToLower(fileName)
+
+	for _, vendoredDir := range vendoredDirs {
+		if strings.HasPrefix(fileName, vendoredDir) || strings.Contains(fileName, "/"+vendoredDir) {
+			return false
+		}
+	}
+
+	for _, generatedSuffix := range generatedSuffixes {
+		if strings.HasSuffix(fileName, generatedSuffix) {
+			return false
+		}
+	}
+
+	_, exists := l.extensions[path.Ext(fileName)]
+	return exists
+}
+
+func (l *LanguageSet) Extensions() []string {
+	extensions := make([]string, 0, len(l.extensions))
+	for extension := range l.extensions {
+		extensions = append(extensions, extension)
+	}
+	sort.Strings(extensions)
+	return extensions
+}
+
+func (l *LanguageSet) Names() []string {
+	return l.names
+}
+
+func Languages() []string {
+	languages := make([]string, 0, len(languageExtensions))
+	for language := range languageExtensions {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
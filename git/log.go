@@ -0,0 +1,177 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Every commit starts with a record separator, then its fields are separated
+// by NUL. With -z git also ends each numstat entry with NUL and writes paths
+// unquoted, so any file name can be parsed. The format ends with NUL too,
+// otherwise a commit without file stats, like a merge, runs into the next.
+// The author is read both after .mailmap and as written in the commit.
+const (
+	logRecordStart = "\x1ecommit "
+	logFormat      = "%x1ecommit %H%x00%P%x00%aN%x00%aE%x00%an%x00%ae%x00%at%x00%cN%x00%cE%x00%ct%x00"
+	logFieldCount  = 10
+)
+
+// With --date-order parents always come before their children, even when
+// commit dates are skewed, so the commit ids are a topological order.
+var logArgs = []string{"log", "-z", "--reverse", "--date-order", "--numstat", "-M", "--no-show-signature", "--format=" + logFormat}
+
+type FileChange struct {
+	Path    string
+	OldPath string
+	Added   int
+	Deleted int
+	Binary  bool
+}
+
+type logEntry struct {
+	hash           string
+	parentHashes   []string
+	authorName     string
+	authorEmail    string
+	rawAuthorName  string
+	rawAuthorEmail string
+	authorDate     time.Time
+	committerName  string
+	committerEmail string
+	committerDate  time.Time
+	files          []*FileChange
+}
+
+func (e *logEntry) author() Identity {
+	return Identity{Name: e.authorName, Email: e.authorEmail}
+}
+
+func (e *logEntry) rawAuthor() Identity {
+	return Identity{Name: e.rawAuthorName, Email: e.rawAuthorEmail}
+}
+
+func parseLog(output string) ([]*logEntry, error) {
+	entries := make([]*logEntry, 0)
+	tokens := strings.Split(output, "\x00")
+
+	for i := 0; i < len(tokens); {
+		token := strings.TrimLeft(tokens[i], "\n")
+		if token == "" {
+			i++
+			continue
+		}
+
+		if !strings.HasPrefix(token, logRecordStart) {
+			return nil, fmt.Errorf("commits log is in a bad format, expected a commit at: '%s'", token)
+		}
+		if i+logFieldCount > len(tokens) {
+			return nil, fmt.Errorf("commits log is truncated at: '%s'", token)
+		}
+
+		fields := append([]string{strings.TrimPrefix(token, logRecordStart)}, tokens[i+1:i+logFieldCount]...)
+		entry, err := parseLogHeader(fields)
+		if err != nil {
+			return nil, err
+		}
+		i += logFieldCount
+
+		for i < len(tokens) {
+			token = strings.TrimLeft(tokens[i], "\n")
+			if token == "" {
+				i++
+				continue
+			}
+			if strings.HasPrefix(token, logRecordStart) {
+				break
+			}
+
+			file, consumed, err := parseNumstat(tokens[i:], token)
+			if err != nil {
+				return nil, fmt.Errorf("commit %s: %w", entry.hash, err)
+			}
+			entry.files = append(entry.files, file)
+			i += consumed
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func parseLogHeader(fields []string) (*logEntry, error) {
+	entry := logEntry{
+		hash:           fields[0],
+		parentHashes:   strings.Fields(fields[1]),
+		authorName:     fields[2],
+		authorEmail:    fields[3],
+		rawAuthorName:  fields[4],
+		rawAuthorEmail: fields[5],
+		committerName:  fields[7],
+		committerEmail: fields[8],
+		files:          make([]*FileChange, 0),
+	}
+
+	if entry.hash == "" {
+		return nil, fmt.Errorf("commits log has a commit without hash")
+	}
+
+	var err error
+	entry.authorDate, err = parseTimestamp(fields[6])
+	if err != nil {
+		return nil, fmt.Errorf("commit %s author date: %w", entry.hash, err)
+	}
+
+	entry.committerDate, err = parseTimestamp(fields[9])
+	if err != nil {
+		return nil, fmt.Errorf("commit %s committer date: %w", entry.hash, err)
+	}
+
+	return &entry, nil
+}
+
+// parseNumstat reads one "added\tdeleted\tpath" entry. Renames have an empty
+// path followed by the old and new paths as the next tokens.
+func parseNumstat(tokens []string, token string) (*FileChange, int, error) {
+	stats := strings.SplitN(token, "\t", 3)
+	if len(stats) != 3 {
+		return nil, 0, fmt.Errorf("file stats are in a bad format: '%s'", token)
+	}
+
+	file := FileChange{Path: stats[2]}
+	if stats[0] == "-" && stats[1] == "-" {
+		file.Binary = true
+	} else {
+		var err error
+		file.Added, err = strconv.Atoi(stats[0])
+		if err != nil {
+			return nil, 0, fmt.Errorf("file stats are in a bad format: '%s'", token)
+		}
+		file.Deleted, err = strconv.Atoi(stats[1])
+		if err != nil {
+			return nil, 0, fmt.Errorf("file stats are in a bad format: '%s'", token)
+		}
+	}
+
+	if file.Path != "" {
+		return &file, 1, nil
+	}
+
+	if len(tokens) < 3 {
+		return nil, 0, fmt.Errorf("renamed file stats are truncated: '%s'", token)
+	}
+	file.OldPath = tokens[1]
+	file.Path = tokens[2]
+
+	return &file, 3, nil
+}
+
+func parseTimestamp(value string) (time.Time, error) {
+	timestamp, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("timestamp is in a bad format: '%s'", value)
+	}
+	return time.Unix(timestamp, 0), nil
+}
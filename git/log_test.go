@@ -0,0 +1,129 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+// logRecord builds the output of logFormat for one commit followed by its
+// numstat entries.
+func logRecord(hash string, parents string, files ...string) string {
+	fields := []string{hash, parents, "Ann", "ann@example.com", "ann", "Ann@Example.com", "1600000000", "Bob", "bob@example.com", "1600000060"}
+	record := "\x1ecommit " + strings.Join(fields, "\x00") + "\x00"
+	if len(files) > 0 {
+		record += "\n" + strings.Join(files, "\x00") + "\x00"
+	}
+	return record
+}
+
+func TestParseLog(t *testing.T) {
+	output := logRecord("a1", "") +
+		logRecord("b2", "a1", "3\t1\tmain.go", "-\t-\tlogo.png", "0\t0\tname with\ttab\nand newline.go") +
+		logRecord("c3", "a1 b2") +
+		logRecord("d4", "c3", "1\t2\t", "old.go", "new.go", "5\t0\tREADME.md")
+
+	entries, err := parseLog(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("parsed %d commits, want 4", len(entries))
+	}
+
+	first := entries[0]
+	if first.hash != "a1" || len(first.parentHashes) != 0 || len(first.files) != 0 {
+		t.Errorf("first commit = %+v", first)
+	}
+	if first.author() != (Identity{Name: "Ann", Email: "ann@example.com"}) || first.rawAuthor() != (Identity{Name: "ann", Email: "Ann@Example.com"}) {
+		t.Errorf("first commit authors = %+v, %+v", first.author(), first.rawAuthor())
+	}
+	if first.authorDate.Unix() != 1600000000 || first.committerDate.Unix() != 1600000060 || first.committerName != "Bob" || first.committerEmail != "bob@example.com" {
+		t.Errorf("first commit committer = %+v", first)
+	}
+
+	files := entries[1].files
+	if len(files) != 3 {
+		t.Fatalf("second commit files = %d, want 3", len(files))
+	}
+	if *files[0] != (FileChange{Path: "main.go", Added: 3, Deleted: 1}) {
+		t.Errorf("file = %+v", files[0])
+	}
+	if *files[1] != (FileChange{Path: "logo.png", Binary: true}) {
+		t.Errorf("binary file = %+v", files[1])
+	}
+	if files[2].Path != "name with\ttab\nand newline.go" {
+		t.Errorf("file name = %q", files[2].Path)
+	}
+
+	merge := entries[2]
+	if merge.hash != "c3" || len(merge.parentHashes) != 2 || len(merge.files) != 0 {
+		t.Errorf("merge commit = %+v", merge)
+	}
+
+	files = entries[3].files
+	if len(files) != 2 || *files[0] != (FileChange{Path: "new.go", OldPath: "old.go", Added: 1, Deleted: 2}) || files[1].Path != "README.md" {
+		t.Errorf("renamed files = %+v", files)
+	}
+}
+
+func TestParseLogErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+	}{
+		{"not a commit", "garbage\x00"},
+		{"truncated header", "\x1ecommit a1\x00\x00Ann\x00"},
+		{"no hash", logRecord("", "")},
+		{"bad date", strings.Replace(logRecord("a1", ""), "1600000000", "yesterday", 1)},
+		{"bad stats", logRecord("a1", "", "x\t1\tmain.go")},
+		{"stats without path", logRecord("a1", "", "1\t2")},
+		{"truncated rename", "\x1ecommit " + strings.Join([]string{"a1", "", "Ann", "a@x", "Ann", "a@x", "1", "Ann", "a@x", "1"}, "\x00") + "\x00\n1\t2\t\x00old.go"},
+	}
+
+	for _, test := range tests {
+		_, err := parseLog(test.output)
+		if err == nil {
+			t.Errorf("%s: parseLog succeeded", test.name)
+		}
+	}
+}
+
+func TestParseLogEmpty(t *testing.T) {
+	entries, err := parseLog("")
+	if err != nil || len(entries) != 0 {
+		t.Errorf("parseLog(\"\") = %v, %v", entries, err)
+	}
+}
+
+func TestParseNumstat(t *testing.T) {
+	tests := []struct {
+		tokens   []string
+		file     FileChange
+		consumed int
+		fails    bool
+	}{
+		{[]string{"10\t4\tsrc/app.go"}, FileChange{Path: "src/app.go", Added: 10, Deleted: 4}, 1, false},
+		{[]string{"-\t-\tfont.woff", "next"}, FileChange{Path: "font.woff", Binary: true}, 1, false},
+		{[]string{"0\t0\t", "a.go", "b.go", "next"}, FileChange{Path: "b.go", OldPath: "a.go"}, 3, false},
+		{[]string{"0\t0\t", "a.go"}, FileChange{}, 0, true},
+		{[]string{"1\t-\tmixed.bin"}, FileChange{}, 0, true},
+		{[]string{"1 2 spaces.go"}, FileChange{}, 0, true},
+	}
+
+	for _, test := range tests {
+		file, consumed, err := parseNumstat(test.tokens, test.tokens[0])
+		if test.fails {
+			if err == nil {
+				t.Errorf("parseNumstat(%q) succeeded", test.tokens)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseNumstat(%q) failed: %s", test.tokens, err)
+			continue
+		}
+		if *file != test.file || consumed != test.consumed {
+			t.Errorf("parseNumstat(%q) = %+v, %d, want %+v, %d", test.tokens, *file, consumed, test.file, test.consumed)
+		}
+	}
+}
@@ -3,6 +3,10 @@ package git
 type MonthCommits struct {
 	Month   YearPeriod
 	Commits []*Commit
+	// LandingCommits are the commits that brought Commits to the analysed
+	// history, the same commits in full history and the mainline commits
+	// that merged them in mainline history.
+	LandingCommits []*Commit
 }
 
 type YearPeriod struct {
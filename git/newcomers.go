@@ -0,0 +1,88 @@
+package git
+
+import "fmt"
+
+const (
+	// EntranceFirstCodeCommit makes a contributor a newcomer on the first
+	// commit changing code of the selected languages.
+	EntranceFirstCodeCommit = "first-code-commit"
+	// EntranceFirstCommit makes a contributor a newcomer on any first commit,
+	// like documentation or build changes.
+	EntranceFirstCommit = "first-commit"
+)
+
+// NewcomerPolicy defines which contributors count as attracted to the
+// project. The zero value counts anyone with a single code commit.
+type NewcomerPolicy struct {
+	Entrance      string
+	MinCommits    int
+	MinActiveDays int
+	// LongTermMonths only counts the contributors still committing the given
+	// months after their entrance, 0 counts everyone.
+	LongTermMonths int
+}
+
+func (p NewcomerPolicy) Validate() error {
+	switch p.Entrance {
+	case "", EntranceFirstCodeCommit, EntranceFirstCommit:
+	default:
+		return fmt.Errorf("unknown newcomer entrance: %s, must be one of: %s, %s", p.Entrance, EntranceFirstCodeCommit, EntranceFirstCommit)
+	}
+
+	if p.MinCommits < 0 || p.MinActiveDays < 0 || p.LongTermMonths < 0 {
+		return fmt.Errorf("newcomer thresholds must not be negative")
+	}
+
+	return nil
+}
+
+// EntranceCommit returns the commit that made the contributor a newcomer, nil
+// when the contributor never made one.
+func (p NewcomerPolicy) EntranceCommit(contributor *Contributor) *Commit {
+	if p.Entrance == EntranceFirstCommit {
+		return contributor.FirstCommit()
+	}
+	return contributor.FirstCodeCommit()
+}
+
+// Considers tells if the commit is a contribution of the entrance kind.
+func (p NewcomerPolicy) Considers(commit *Commit) bool {
+	return p.Entrance == EntranceFirstCommit || commit.HasCode
+}
+
+// Counts tells if the contributor reaches the thresholds, only the commits of
+// the entrance kind are considered.
+func (p NewcomerPolicy) Counts(contributor *Contributor) bool {
+	entrance := p.EntranceCommit(contributor)
+	if entrance == nil {
+		return false
+	}
+
+	commits := 0
+	activeDays := make(map[string]interface{})
+	var lastCommit *Commit
+	for _, commit := range contributor.Commits {
+		if !p.Considers(commit) {
+			continue
+		}
+
+		commits++
+		activeDays[commit.Date.UTC().Format("2006-01-02")] = nil
+		if lastCommit == nil || commit.Date.After(lastCommit.Date) {
+			lastCommit = commit
+		}
+	}
+
+	if commits < p.MinCommits || len(activeDays) < p.MinActiveDays {
+		return false
+	}
+
+	if p.LongTermMonths > 0 {
+		longTerm := entrance.Date.AddDate(0, p.LongTermMonths, 0)
+		if lastCommit.Date.Before(longTerm) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,153 @@
+package git
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	githubHost = "github.com"
+	localHost  = "local"
+)
+
+var (
+	scpLikeURL          = regexp.MustCompile(`^(?:[\w.-]+@)?([\w.-]+):(.+)$`)
+	invalidKeyCharacter = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+)
+
+type Remote struct {
+	URL       string
+	Host      string
+	Path      string
+	Namespace string
+	Project   string
+}
+
+func ParseRemote(repository string) (*Remote, error) {
+	repository = strings.TrimSpace(repository)
+	if repository == "" {
+		return nil, fmt.Errorf("repository must not be empty")
+	}
+
+	if isLocalPath(repository) || (isDirectory(repository) && !isGithubShorthand(repository)) {
+		absolutePath, err := filepath.Abs(repository)
+		if err != nil {
+			return nil, fmt.Errorf("fail to resolve local repository path '%s': %w", repository, err)
+		}
+		return newRemote(absolutePath, localHost, absolutePath)
+	}
+
+	if strings.Contains(repository, "://") {
+		remoteURL, err := url.Parse(repository)
+		if err != nil {
+			return nil, fmt.Errorf("repository url is in a bad format: '%s': %w", repository, err)
+		}
+
+		if remoteURL.Scheme == "file" {
+			return newRemote(repository, localHost, remoteURL.Path)
+		}
+		return newRemote(repository, remoteURL.Hostname(), remoteURL.Path)
+	}
+
+	if match := scpLikeURL.FindStringSubmatch(repository); match != nil {
+		return newRemote(repository, match[1], match[2])
+	}
+
+	if isGithubShorthand(repository) {
+		return newRemote(fmt.Sprintf("https://github.com/%s.git", repository), githubHost, repository)
+	}
+
+	return nil, fmt.Errorf("repository must be a clone url, a local directory or in format namespace/project: '%s'", repository)
+}
+
+func newRemote(remoteURL string, host string, remotePath string) (*Remote, error) {
+	remotePath = strings.TrimSuffix(strings.Trim(remotePath, "/"), ".git")
+	if remotePath == "" {
+		return nil, fmt.Errorf("repository url has no path: '%s'", remoteURL)
+	}
+	// The host and path name the cache directory, so they must not climb out
+	// of it.
+	if !isPathSegment(host) {
+		return nil, fmt.Errorf("repository url has a bad host: '%s'", remoteURL)
+	}
+	for _, segment := range strings.Split(remotePath, "/") {
+		if !isPathSegment(segment) {
+			return nil, fmt.Errorf("repository url has a bad path: '%s'", remoteURL)
+		}
+	}
+
+	r := Remote{
+		URL:     remoteURL,
+		Host:    host,
+		Path:    remotePath,
+		Project: remotePath,
+	}
+
+	if i := strings.LastIndex(remotePath, "/"); i >= 0 {
+		r.Namespace = remotePath[:i]
+		r.Project = remotePath[i+1:]
+	}
+
+	return &r, nil
+}
+
+func (r *Remote) Key() string {
+	parts := strings.Split(r.Path, "/")
+	if r.Host != githubHost {
+		parts = append([]string{r.Host}, parts...)
+	}
+
+	for i, part := range parts {
+		parts[i] = invalidKeyCharacter.ReplaceAllString(part, "_")
+	}
+
+	return strings.Join(parts, ":")
+}
+
+func (r *Remote) CacheDir() string {
+	if r.Host == githubHost {
+		return r.Path
+	}
+	return filepath.Join(r.Host, r.Path)
+}
+
+func (r *Remote) LocalDir() (string, bool) {
+	if r.Host != localHost {
+		return "", false
+	}
+
+	remoteURL, err := url.Parse(r.URL)
+	if err == nil && remoteURL.Scheme == "file" {
+		return remoteURL.Path, true
+	}
+	return r.URL, true
+}
+
+func (r *Remote) String() string {
+	if r.Host == githubHost {
+		return r.Path
+	}
+	return r.URL
+}
+
+func isGithubShorthand(repository string) bool {
+	parts := strings.Split(repository, "/")
+	return len(parts) == 2 && isPathSegment(parts[0]) && isPathSegment(parts[1])
+}
+
+func isPathSegment(segment string) bool {
+	return segment != "" && segment != "." && segment != ".." && !strings.ContainsAny(segment, "/\\")
+}
+
+func isLocalPath(repository string) bool {
+	return strings.HasPrefix(repository, "/") || strings.HasPrefix(repository, "./") || strings.HasPrefix(repository, "../")
+}
+
+func isDirectory(repository string) bool {
+	info, err := os.Stat(repository)
+	return err == nil && info.IsDir()
+}
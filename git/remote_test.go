@@ -0,0 +1,79 @@
+package git
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseRemote(t *testing.T) {
+	tests := []struct {
+		repository string
+		url        string
+		key        string
+		cacheDir   string
+	}{
+		{"diegocsandrim/sonarminer", "https://github.com/diegocsandrim/sonarminer.git", "diegocsandrim:sonarminer", "diegocsandrim/sonarminer"},
+		{"https://github.com/urfave/cli.git", "https://github.com/urfave/cli.git", "urfave:cli", "urfave/cli"},
+		{"https://gitlab.com/group/sub/project", "https://gitlab.com/group/sub/project", "gitlab.com:group:sub:project", "gitlab.com/group/sub/project"},
+		{"git@gitlab.com:group/project.git", "git@gitlab.com:group/project.git", "gitlab.com:group:project", "gitlab.com/group/project"},
+		{"/srv/repos/my project", "/srv/repos/my project", "local:srv:repos:my_project", "local/srv/repos/my project"},
+		{"file:///srv/repos/project.git", "file:///srv/repos/project.git", "local:srv:repos:project", "local/srv/repos/project"},
+	}
+
+	for _, test := range tests {
+		remote, err := ParseRemote(test.repository)
+		if err != nil {
+			t.Errorf("ParseRemote(%q) failed: %s", test.repository, err)
+			continue
+		}
+		if remote.URL != test.url {
+			t.Errorf("ParseRemote(%q).URL = %q, want %q", test.repository, remote.URL, test.url)
+		}
+		if remote.Key() != test.key {
+			t.Errorf("ParseRemote(%q).Key() = %q, want %q", test.repository, remote.Key(), test.key)
+		}
+		if remote.CacheDir() != filepath.FromSlash(test.cacheDir) {
+			t.Errorf("ParseRemote(%q).CacheDir() = %q, want %q", test.repository, remote.CacheDir(), test.cacheDir)
+		}
+	}
+}
+
+func TestParseRemoteRejectsPathsOutOfTheCache(t *testing.T) {
+	repositories := []string{
+		"",
+		"https://evil.example/../../../home/user",
+		"https://evil.example/group/./project",
+		"https:///group/project",
+		"host:../../../root",
+		"..:group/project",
+		"x/..",
+		"../..",
+		"./.",
+		"file:///srv/../root",
+	}
+
+	for _, repository := range repositories {
+		remote, err := ParseRemote(repository)
+		if err == nil && !strings.HasPrefix(remote.Host, localHost) {
+			t.Errorf("ParseRemote(%q) = %+v, want an error", repository, remote)
+			continue
+		}
+		if err == nil {
+			relativeDir, _ := filepath.Rel(GitBaseDir, filepath.Join(GitBaseDir, remote.CacheDir()))
+			if relativeDir == "." || strings.HasPrefix(relativeDir, "..") {
+				t.Errorf("ParseRemote(%q).CacheDir() = %q is out of the cache", repository, remote.CacheDir())
+			}
+		}
+	}
+}
+
+func TestForceCloneRefusesDirectoriesOutOfTheCache(t *testing.T) {
+	gitRepo := NewGitRepo(&Remote{URL: "https://example.com/x", Host: "..", Path: ".."})
+
+	err := gitRepo.ForceClone(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "is not in the cache directory") {
+		t.Errorf("ForceClone() = %v, want a cache directory error", err)
+	}
+}
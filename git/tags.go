@@ -0,0 +1,70 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var tagArgs = []string{"for-each-ref", "--format=%(refname:strip=2)%00%(objectname)%00%(*objectname)%00%(creatordate:unix)", "refs/tags"}
+
+type Tag struct {
+	Name   string
+	Commit *Commit
+	// Date is when the tag was created for annotated tags, the commit date
+	// for lightweight tags.
+	Date time.Time
+}
+
+// loadTags reads the tags of the loaded commits, tags of other objects or of
+// commits out of the history are left out.
+func (g *GitRepo) loadTags(ctx context.Context) error {
+	output, err := g.git(ctx, tagArgs...)
+	if err != nil {
+		return err
+	}
+
+	g.tags = make([]*Tag, 0)
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 4 {
+			return fmt.Errorf("tags are in a bad format, expected 4 fields at: '%s'", line)
+		}
+
+		hash := fields[1]
+		if fields[2] != "" {
+			hash = fields[2]
+		}
+		commit := g.commits[hash]
+		if commit == nil {
+			continue
+		}
+
+		date, err := parseTimestamp(fields[3])
+		if err != nil {
+			return err
+		}
+
+		g.tags = append(g.tags, &Tag{Name: fields[0], Commit: commit, Date: date})
+	}
+
+	sort.SliceStable(g.tags, func(i, j int) bool {
+		if !g.tags[i].Date.Equal(g.tags[j].Date) {
+			return g.tags[i].Date.Before(g.tags[j].Date)
+		}
+		return g.tags[i].Commit.Id < g.tags[j].Commit.Id
+	})
+
+	return nil
+}
+
+// Tags returns the tags of the loaded commits, from the oldest.
+func (g *GitRepo) Tags() []*Tag {
+	return g.tags
+}
@@ -0,0 +1,63 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const removeWorktreeTimeout = time.Minute
+
+func NewWorktreeGitRepo(remote *Remote) (*GitRepo, error) {
+	sourceDir, ok := remote.LocalDir()
+	if !ok {
+		return nil, fmt.Errorf("worktree mode requires a local repository, got: %s", remote)
+	}
+
+	g := GitRepo{
+		remote:         remote,
+		worktreeSource: sourceDir,
+		worktreeDir:    path.Join(GitBaseDir, "worktrees", strings.ReplaceAll(remote.Key(), ":", "_")+"-"+uuid.NewString()),
+	}
+	g.cmdFactory = newGitCmdFactory(g.ProjectDir())
+
+	return &g, nil
+}
+
+func (g *GitRepo) addWorktree(ctx context.Context) error {
+	_, err := newGitCmdFactory(g.worktreeSource).Run(ctx, "git", "worktree", "add", "--detach", g.worktreeDir, "HEAD")
+	if err != nil {
+		return fmt.Errorf("fail to add worktree: %w", err)
+	}
+	return nil
+}
+
+// removeWorktree does not take a context, the worktree must be removed even
+// when the analysis was canceled.
+func (g *GitRepo) removeWorktree() error {
+	ctx, cancel := context.WithTimeout(context.Background(), removeWorktreeTimeout)
+	defer cancel()
+
+	sourceCmd := newGitCmdFactory(g.worktreeSource)
+	_, err := sourceCmd.Run(ctx, "git", "worktree", "remove", "--force", g.worktreeDir)
+	if err != nil {
+		log.Printf("fail to remove worktree %s: %s", g.worktreeDir, err.Error())
+
+		err = os.RemoveAll(g.worktreeDir)
+		if err != nil {
+			return fmt.Errorf("fail to remove worktree: %w", err)
+		}
+
+		_, err = sourceCmd.Run(ctx, "git", "worktree", "prune")
+		if err != nil {
+			return fmt.Errorf("fail to prune worktrees: %w", err)
+		}
+	}
+	return nil
+}
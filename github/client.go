@@ -0,0 +1,134 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultBaseURL = "https://api.github.com"
+
+	defaultTimeout    = 30 * time.Second
+	defaultRetries    = 3
+	defaultRetryDelay = time.Second
+	maxRateLimitWait  = 15 * time.Minute
+)
+
+type Client struct {
+	BaseURL    string
+	Token      string
+	Retries    int
+	RetryDelay time.Duration
+	HTTPClient *http.Client
+}
+
+func NewClient(baseURL string, token string) *Client {
+	c := Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		Token:      token,
+		Retries:    defaultRetries,
+		RetryDelay: defaultRetryDelay,
+		HTTPClient: &http.Client{Timeout: defaultTimeout},
+	}
+	return &c
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, result interface{}) error {
+	var err error
+	for attempt := 0; attempt <= c.Retries; attempt++ {
+		if attempt > 0 {
+			delay := c.retryDelay(err)
+			log.Printf("retrying GET %s in %s (%d/%d): %s", path, delay, attempt, c.Retries, err.Error())
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		err = c.getOnce(ctx, path, query, result)
+		if err == nil || !retryable(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// retryDelay waits for the rate limit window to reset when GitHub says when it
+// does, the search API only allows a few requests per minute.
+func (c *Client) retryDelay(err error) time.Duration {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.RetryAfter <= 0 {
+		return c.RetryDelay
+	}
+	if apiErr.RetryAfter > maxRateLimitWait {
+		return maxRateLimitWait
+	}
+	return apiErr.RetryAfter
+}
+
+func (c *Client) getOnce(ctx context.Context, path string, query url.Values, result interface{}) error {
+	reqURL := fmt.Sprintf("%s/%s", c.BaseURL, path)
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return fmt.Errorf("fail to create a request to %s: %w", path, err)
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return &NetworkError{Path: path, Err: err}
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return newAPIError(path, res)
+	}
+
+	err = json.NewDecoder(res.Body).Decode(result)
+	if err != nil {
+		return fmt.Errorf("fail to decode %s response from api: %w", path, err)
+	}
+
+	return nil
+}
+
+func rateLimitReset(res *http.Response) time.Duration {
+	retryAfter, err := strconv.Atoi(res.Header.Get("Retry-After"))
+	if err == nil {
+		return time.Duration(retryAfter) * time.Second
+	}
+
+	if res.Header.Get("X-RateLimit-Remaining") != "0" {
+		return 0
+	}
+
+	reset, err := strconv.ParseInt(res.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return time.Until(time.Unix(reset, 0)) + time.Second
+}
@@ -0,0 +1,73 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+type APIError struct {
+	Path       string
+	StatusCode int
+	Message    string
+	RetryAfter time.Duration
+}
+
+func newAPIError(path string, res *http.Response) *APIError {
+	e := APIError{
+		Path:       path,
+		StatusCode: res.StatusCode,
+		RetryAfter: rateLimitReset(res),
+	}
+
+	body, _ := io.ReadAll(res.Body)
+	data := struct {
+		Message string `json:"message"`
+	}{}
+	if json.Unmarshal(body, &data) == nil {
+		e.Message = data.Message
+	}
+
+	return &e
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("github api GET %s failed, status code: %d", e.Path, e.StatusCode)
+	}
+	return fmt.Sprintf("github api GET %s failed, status code: %d: %s", e.Path, e.StatusCode, e.Message)
+}
+
+func (e *APIError) rateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests || (e.StatusCode == http.StatusForbidden && e.RetryAfter > 0)
+}
+
+type NetworkError struct {
+	Path string
+	Err  error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("github api GET %s failed: %s", e.Path, e.Err.Error())
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+func retryable(err error) bool {
+	var networkErr *NetworkError
+	if errors.As(err, &networkErr) {
+		return true
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.rateLimited() || apiErr.StatusCode >= 500
+	}
+
+	return false
+}
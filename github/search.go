@@ -0,0 +1,165 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	searchPageSize = 100
+	// The search API never returns more than the first 1000 results of a query.
+	searchMaxResults = 1000
+)
+
+type SearchQuery struct {
+	Languages       []string
+	MinStars        int
+	MaxStars        int
+	CreatedAfter    string
+	CreatedBefore   string
+	PushedAfter     string
+	IncludeForks    bool
+	IncludeArchived bool
+	Extra           string
+}
+
+func (q SearchQuery) Validate() error {
+	for _, date := range []string{q.CreatedAfter, q.CreatedBefore, q.PushedAfter} {
+		if date == "" {
+			continue
+		}
+		_, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return fmt.Errorf("date must be in format YYYY-MM-DD: '%s'", date)
+		}
+	}
+
+	if q.MaxStars > 0 && q.MaxStars < q.MinStars {
+		return fmt.Errorf("max stars %d is lower than min stars %d", q.MaxStars, q.MinStars)
+	}
+
+	return nil
+}
+
+func (q SearchQuery) String() string {
+	terms := make([]string, 0)
+	if q.Extra != "" {
+		terms = append(terms, q.Extra)
+	}
+
+	for _, language := range q.Languages {
+		terms = append(terms, "language:"+language)
+	}
+
+	switch {
+	case q.MinStars > 0 && q.MaxStars > 0:
+		terms = append(terms, fmt.Sprintf("stars:%d..%d", q.MinStars, q.MaxStars))
+	case q.MinStars > 0:
+		terms = append(terms, fmt.Sprintf("stars:>=%d", q.MinStars))
+	case q.MaxStars > 0:
+		terms = append(terms, fmt.Sprintf("stars:<=%d", q.MaxStars))
+	}
+
+	switch {
+	case q.CreatedAfter != "" && q.CreatedBefore != "":
+		terms = append(terms, fmt.Sprintf("created:%s..%s", q.CreatedAfter, q.CreatedBefore))
+	case q.CreatedAfter != "":
+		terms = append(terms, "created:>="+q.CreatedAfter)
+	case q.CreatedBefore != "":
+		terms = append(terms, "created:<="+q.CreatedBefore)
+	}
+
+	if q.PushedAfter != "" {
+		terms = append(terms, "pushed:>="+q.PushedAfter)
+	}
+
+	if q.IncludeForks {
+		terms = append(terms, "fork:true")
+	}
+
+	if !q.IncludeArchived {
+		terms = append(terms, "archived:false")
+	}
+
+	return strings.Join(terms, " ")
+}
+
+type Repository struct {
+	FullName  string
+	CloneURL  string
+	Language  string
+	Stars     int
+	Fork      bool
+	Archived  bool
+	CreatedAt time.Time
+	PushedAt  time.Time
+}
+
+func (c *Client) SearchRepositories(ctx context.Context, query SearchQuery, sort string, limit int) ([]*Repository, error) {
+	if limit <= 0 || limit > searchMaxResults {
+		limit = searchMaxResults
+	}
+
+	repositories := make([]*Repository, 0, limit)
+
+	for page := 1; len(repositories) < limit; page++ {
+		params := url.Values{}
+		params.Set("q", query.String())
+		params.Set("per_page", fmt.Sprint(searchPageSize))
+		params.Set("page", fmt.Sprint(page))
+		if sort != "" {
+			params.Set("sort", sort)
+			params.Set("order", "desc")
+		}
+
+		data := struct {
+			TotalCount        int  `json:"total_count"`
+			IncompleteResults bool `json:"incomplete_results"`
+			Items             []struct {
+				FullName        string    `json:"full_name"`
+				CloneURL        string    `json:"clone_url"`
+				Language        string    `json:"language"`
+				StargazersCount int       `json:"stargazers_count"`
+				Fork            bool      `json:"fork"`
+				Archived        bool      `json:"archived"`
+				CreatedAt       time.Time `json:"created_at"`
+				PushedAt        time.Time `json:"pushed_at"`
+			} `json:"items"`
+		}{}
+
+		err := c.get(ctx, "search/repositories", params, &data)
+		if err != nil {
+			return nil, err
+		}
+
+		if data.IncompleteResults {
+			log.Printf("github search timed out, results of page %d may be incomplete", page)
+		}
+
+		for _, item := range data.Items {
+			if len(repositories) == limit {
+				break
+			}
+			repositories = append(repositories, &Repository{
+				FullName:  item.FullName,
+				CloneURL:  item.CloneURL,
+				Language:  item.Language,
+				Stars:     item.StargazersCount,
+				Fork:      item.Fork,
+				Archived:  item.Archived,
+				CreatedAt: item.CreatedAt,
+				PushedAt:  item.PushedAt,
+			})
+		}
+
+		if len(data.Items) < searchPageSize || page*searchPageSize >= data.TotalCount || page*searchPageSize >= searchMaxResults {
+			return repositories, nil
+		}
+	}
+
+	return repositories, nil
+}
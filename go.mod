@@ -5,4 +5,7 @@ go 1.16
 require (
 	github.com/google/uuid v1.3.0
 	github.com/urfave/cli/v2 v2.10.3
+	github.com/xitongsys/parquet-go v1.6.2
+	github.com/xitongsys/parquet-go-source v0.0.0-20200817004010-026bad9b25d0
+	gopkg.in/yaml.v3 v3.0.1
 )
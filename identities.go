@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/diegocsandrim/sonarminer/git"
+	"github.com/diegocsandrim/sonarminer/settings"
+	"github.com/diegocsandrim/sonarminer/strategy"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	identitiesFormatTable = "table"
+	identitiesFormatCSV   = "csv"
+)
+
+type repositoryIdentityMerge struct {
+	projectKey string
+	*git.IdentityMerge
+}
+
+func identitiesFlags(config *settings.Config) []cli.Flag {
+	return append([]cli.Flag{
+		reposFileFlag(),
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: fmt.Sprintf("Output format, one of: %s, %s", identitiesFormatTable, identitiesFormatCSV),
+			Value: identitiesFormatTable,
+		},
+	}, strategyFlags(config)...)
+}
+
+func auditIdentities(c *cli.Context, config settings.Config) error {
+	format := c.String("format")
+	if format != identitiesFormatTable && format != identitiesFormatCSV {
+		return fmt.Errorf("unknown identities format: %s, must be one of: %s, %s", format, identitiesFormatTable, identitiesFormatCSV)
+	}
+
+	repositories, err := repositoryArgs(c)
+	if err != nil {
+		return err
+	}
+
+	if len(repositories) == 0 {
+		return fmt.Errorf("must provide at least one repository to audit")
+	}
+
+	merges := make([]*repositoryIdentityMerge, 0)
+	for _, repository := range repositories {
+		repositoryMerges, err := repositoryIdentityMerges(c.Context, config, repository)
+		if err != nil {
+			return cli.Exit(err.Error(), 1)
+		}
+		merges = append(merges, repositoryMerges...)
+	}
+
+	if format == identitiesFormatCSV {
+		return writeIdentitiesCSV(os.Stdout, merges)
+	}
+	return writeIdentitiesTable(os.Stdout, merges)
+}
+
+func repositoryIdentityMerges(ctx context.Context, config settings.Config, repository string) ([]*repositoryIdentityMerge, error) {
+	remote, err := git.ParseRemote(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	gitRepo, err := strategy.Load(ctx, remote, config)
+	if err != nil {
+		return nil, err
+	}
+	defer gitRepo.Close()
+
+	projectKey := remote.Key()
+	merges := make([]*repositoryIdentityMerge, 0)
+	for _, merge := range gitRepo.IdentityMerges() {
+		merges = append(merges, &repositoryIdentityMerge{projectKey: projectKey, IdentityMerge: merge})
+	}
+
+	return merges, nil
+}
+
+func writeIdentitiesTable(w io.Writer, merges []*repositoryIdentityMerge) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "PROJECT\tCONTRIBUTOR\tIDENTITY\tCOMMITS\tREASON")
+	for _, m := range merges {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%d\t%s\n", m.projectKey, m.Contributor, m.Identity, m.Commits, m.Reason)
+	}
+	return table.Flush()
+}
+
+func writeIdentitiesCSV(w io.Writer, merges []*repositoryIdentityMerge) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{"project", "contributor", "name", "email", "commits", "reason"})
+	if err != nil {
+		return err
+	}
+
+	for _, m := range merges {
+		err = writer.Write([]string{m.projectKey, m.Contributor, m.Identity.Name, m.Identity.Email, strconv.Itoa(m.Commits), m.Reason})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
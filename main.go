@@ -1,14 +1,24 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
+	"github.com/diegocsandrim/sonarminer/checkpoint"
+	"github.com/diegocsandrim/sonarminer/export"
+	"github.com/diegocsandrim/sonarminer/git"
+	"github.com/diegocsandrim/sonarminer/metrics"
+	"github.com/diegocsandrim/sonarminer/qualityanalyzers"
 	"github.com/diegocsandrim/sonarminer/settings"
 	"github.com/diegocsandrim/sonarminer/sonar"
 	"github.com/diegocsandrim/sonarminer/strategy"
+	"github.com/google/uuid"
 	"github.com/urfave/cli/v2"
 )
 
@@ -17,110 +27,622 @@ func main() {
 		SonarKey: "",
 		SonarURL: "",
 	}
+	planFormat := strategy.PlanFormatTable
 
 	app := &cli.App{
 		Usage: "analyse the project history using sonnar-scanner",
 		Commands: []*cli.Command{
 			{
-				Name:    "analyse",
-				Aliases: []string{"a"},
-				Usage:   "analyse the repository history",
-				Flags: []cli.Flag{
+				Name:        "analyse",
+				Aliases:     []string{"a"},
+				Usage:       "analyse the repository history",
+				Description: strategy.Usage(),
+				Flags: append(append(sonarFlags(&config),
+					reposFileFlag(),
+					&cli.BoolFlag{
+						Name:        "resume",
+						Usage:       "Skip the commits already analysed by a previous run with the same strategy",
+						Destination: &(config.Resume),
+					},
+					&cli.IntFlag{
+						Name:        "parallel",
+						Usage:       "Number of repositories to analyse concurrently",
+						Value:       1,
+						Destination: &(config.Parallel),
+					},
+					&cli.DurationFlag{
+						Name:        "scan-timeout",
+						Usage:       "Maximum time to scan one commit and process it in Sonarqube, 0 for no limit",
+						Value:       time.Hour,
+						Destination: &(config.ScanTimeout),
+					},
+					&cli.BoolFlag{
+						Name:        "keep-going",
+						Usage:       "Keep analysing the other repositories when one of them fails",
+						Destination: &(config.KeepGoing),
+					},
 					&cli.StringFlag{
-						Name:        "sonarkey",
-						Usage:       "Sonarqube token",
-						EnvVars:     []string{"SONAR_TOKEN"},
-						Destination: &(config.SonarKey),
+						Name:  "sonar-config",
+						Usage: "YAML file with the sonar properties for all repositories and per repository overrides",
+					},
+					&cli.StringSliceFlag{
+						Name:  "sonar-property",
+						Usage: "Extra sonar property in format key=value, e.g. sonar.exclusions=**/testdata/**",
 					},
+				), strategyFlags(&config)...),
+				Action: func(c *cli.Context) error {
+					err := applyStrategyFlags(c, &config)
+					if err != nil {
+						return err
+					}
+
+					err = applySonarPropertyFlags(c, &config)
+					if err != nil {
+						return err
+					}
+
+					err = ensureSonarToken(c.Context, &config)
+					if err != nil {
+						return err
+					}
+
+					repositories, err := repositoryArgs(c)
+					if err != nil {
+						return err
+					}
+
+					if len(repositories) == 0 {
+						return fmt.Errorf("must provide at least one repository to analyse")
+					}
+
+					results, err := analyseRepositories(c.Context, newRepositoryJobs(config, repositories), config.Parallel, config.KeepGoing)
+					if err != nil {
+						return err
+					}
+
+					return analysisFailure(c.Context, results)
+				},
+			},
+			{
+				Name:   "discover",
+				Usage:  "search GitHub for repositories to mine and print them in the --repos-file format",
+				Flags:  discoverFlags(),
+				Action: discoverRepositories,
+			},
+			{
+				Name:      "run",
+				Usage:     "run the mining campaign described in a YAML file",
+				ArgsUsage: "campaign.yaml",
+				Action: func(c *cli.Context) error {
+					if c.Args().Len() != 1 {
+						return fmt.Errorf("must provide exactly one campaign file")
+					}
+
+					return runCampaign(c.Context, c.Args().First())
+				},
+			},
+			{
+				Name:        "plan",
+				Aliases:     []string{"p"},
+				Usage:       "print the commits the analysis would run on, without running the scanner",
+				Description: strategy.Usage(),
+				Flags: append([]cli.Flag{
+					reposFileFlag(),
 					&cli.StringFlag{
-						Name:        "sonarurl",
-						Usage:       "Sonarqube URL",
-						EnvVars:     []string{"SONAR_URL"},
-						Value:       "http://127.0.0.1:9000",
-						Destination: &(config.SonarURL),
+						Name:        "format",
+						Usage:       fmt.Sprintf("Output format, one of: %s, %s, %s", strategy.PlanFormatTable, strategy.PlanFormatJSON, strategy.PlanFormatCSV),
+						Value:       strategy.PlanFormatTable,
+						Destination: &planFormat,
 					},
+				}, strategyFlags(&config)...),
+				Action: func(c *cli.Context) error {
+					err := applyStrategyFlags(c, &config)
+					if err != nil {
+						return err
+					}
+
+					err = strategy.ValidatePlanFormat(planFormat)
+					if err != nil {
+						return err
+					}
+
+					repositories, err := repositoryArgs(c)
+					if err != nil {
+						return err
+					}
+
+					if len(repositories) == 0 {
+						return fmt.Errorf("must provide at least one repository to plan")
+					}
+
+					plannedAnalyses := make([]*strategy.PlannedAnalysis, 0)
+					for _, repository := range repositories {
+						repositoryPlan, err := planRepository(c.Context, config, repository)
+						if err != nil {
+							return cli.Exit(err.Error(), 1)
+						}
+						plannedAnalyses = append(plannedAnalyses, repositoryPlan...)
+					}
+
+					return strategy.WritePlan(os.Stdout, planFormat, plannedAnalyses)
+				},
+			},
+			{
+				Name:  "identities",
+				Usage: "print the author identities merged into each contributor, to review the .mailmap, alias file and merge heuristics",
+				Flags: identitiesFlags(&config),
+				Action: func(c *cli.Context) error {
+					err := applyStrategyFlags(c, &config)
+					if err != nil {
+						return err
+					}
+
+					return auditIdentities(c, config)
+				},
+			},
+			{
+				Name:  "collect",
+				Usage: "collect the measures of the analyses already submitted to Sonarqube",
+				Flags: append(sonarFlags(&config),
+					reposFileFlag(),
 					&cli.StringFlag{
 						Name:        "strategy",
-						Usage:       "Strategy to analyse the repositories, one of: ALL, PERIOD, BATCH, INTEREST",
+						Usage:       "Strategy used to analyse the repositories, to match analyses with commits and contributors",
 						Value:       "PERIOD",
 						Destination: &(config.Strategy),
 					},
-					&cli.IntFlag{
-						Name:        "interval",
-						Usage:       "When using strategy=PERIOD, set the interval in months",
-						Value:       6,
-						Destination: &(config.PeriodInterval),
+					&cli.StringSliceFlag{
+						Name:  "metrics",
+						Usage: "Metric keys to collect",
+						Value: cli.NewStringSlice(metrics.DefaultMetrics...),
 					},
-					&cli.IntFlag{
-						Name:        "batch",
-						Usage:       "Batch size when using strategy=BATCH",
-						Value:       20,
-						Destination: &(config.BatchSize),
+					&cli.StringFlag{
+						Name:  "output",
+						Usage: "CSV file to also write the measures to",
 					},
-				},
+				),
 				Action: func(c *cli.Context) error {
-					if config.SonarKey == "" {
-						token, err := sonar.NewToken(config.SonarURL)
+					repositories, err := repositoryArgs(c)
+					if err != nil {
+						return err
+					}
+
+					if len(repositories) == 0 {
+						return fmt.Errorf("must provide at least one repository to collect")
+					}
+
+					err = ensureSonarToken(c.Context, &config)
+					if err != nil {
+						return err
+					}
+
+					measures := make([]*metrics.Measure, 0)
+					for _, repository := range repositories {
+						repositoryMeasures, err := collectRepository(c.Context, config, repository, c.StringSlice("metrics"))
 						if err != nil {
-							return fmt.Errorf("token not provided, fail to create one with default user/password: %w", err)
+							return cli.Exit(err.Error(), 1)
 						}
-						config.SonarKey = token
+						measures = append(measures, repositoryMeasures...)
 					}
 
-					if c.Args().Len() == 0 {
-						return fmt.Errorf("must provide at least one repository to analyse")
+					if c.String("output") == "" {
+						return nil
+					}
+
+					return writeMeasures(c.String("output"), export.FormatCSV, export.LayoutLong, measures)
+				},
+			},
+			{
+				Name:  "export",
+				Usage: "export the collected measures for analysis in other tools",
+				Flags: []cli.Flag{
+					reposFileFlag(),
+					&cli.StringFlag{
+						Name:  "format",
+						Usage: fmt.Sprintf("Output format, one of: %s, %s, %s", export.FormatCSV, export.FormatJSONL, export.FormatParquet),
+						Value: export.FormatCSV,
+					},
+					&cli.StringFlag{
+						Name:  "layout",
+						Usage: fmt.Sprintf("%s for one row per analysis and metric, %s for one row per analysis with one column per metric", export.LayoutLong, export.LayoutWide),
+						Value: export.LayoutLong,
+					},
+					&cli.StringFlag{
+						Name:  "output",
+						Usage: "File to write the measures to, defaults to stdout",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					err := export.Validate(c.String("format"), c.String("layout"))
+					if err != nil {
+						return err
+					}
+
+					repositories, err := repositoryArgs(c)
+					if err != nil {
+						return err
 					}
-					repositories := c.Args().Slice()
+
+					projectKeys := make([]string, 0, len(repositories))
 					for _, repository := range repositories {
-						err := runRepository(config, repository)
+						remote, err := git.ParseRemote(repository)
 						if err != nil {
-							return cli.Exit(err.Error(), 1)
+							return err
 						}
+						projectKeys = append(projectKeys, remote.Key())
+					}
+
+					measures, err := metrics.Load(git.GitBaseDir, projectKeys)
+					if err != nil {
+						return err
 					}
 
-					return nil
+					return writeMeasures(c.String("output"), c.String("format"), c.String("layout"), measures)
 				},
 			},
 		},
 	}
 
-	err := app.Run(os.Args)
+	err := app.RunContext(interruptContext(), os.Args)
 	if err != nil {
 		log.Fatal(err)
 	}
 }
 
-func runRepository(config settings.Config, repositoryFullName string) error {
-	repositoryParts := strings.Split(repositoryFullName, "/")
-	if len(repositoryParts) != 2 {
-		return cli.Exit("argument must be in format namespace/project", 1)
+func analyseRepositories(ctx context.Context, jobs []*repositoryJob, parallel int, keepGoing bool) ([]*repositoryResult, error) {
+	err := qualityanalyzers.RemoveOrphanContainers(ctx)
+	if err != nil {
+		log.Printf("failed to remove orphan scanner containers: %s", err.Error())
+	}
+
+	results := runRepositories(ctx, jobs, parallel, keepGoing)
+
+	return results, writeSummary(os.Stdout, results)
+}
+
+func analysisFailure(ctx context.Context, results []*repositoryResult) error {
+	if ctx.Err() != nil {
+		return cli.Exit("interrupted", 130)
+	}
+
+	failures := countFailures(results)
+	if failures > 0 {
+		return cli.Exit(fmt.Sprintf("%d of %d repositories were not analysed", failures, len(results)), 1)
+	}
+
+	return nil
+}
+
+// interruptContext is canceled on the first interrupt, so the running
+// analyses stop their scanners and clean up. A second interrupt removes the
+// scanner containers and exits right away.
+func interruptContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-signals
+		log.Printf("interrupted, stopping the analyses, interrupt again to force...")
+		cancel()
+
+		<-signals
+		log.Printf("interrupted again, removing scanner containers...")
+		removeCtx, removeCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer removeCancel()
+		err := qualityanalyzers.RemoveOwnContainers(removeCtx)
+		if err != nil {
+			log.Printf("failed to remove scanner containers: %s", err.Error())
+		}
+		os.Exit(130)
+	}()
+
+	return ctx
+}
+
+func sonarFlags(config *settings.Config) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "sonarkey",
+			Usage:       "Sonarqube token",
+			EnvVars:     []string{"SONAR_TOKEN"},
+			Destination: &(config.SonarKey),
+		},
+		&cli.StringFlag{
+			Name:        "sonarurl",
+			Usage:       "Sonarqube URL",
+			EnvVars:     []string{"SONAR_URL"},
+			Value:       "http://127.0.0.1:9000",
+			Destination: &(config.SonarURL),
+		},
+		&cli.StringFlag{
+			Name:        "sonaruser",
+			Usage:       "Sonarqube user to create a token when none is provided",
+			EnvVars:     []string{"SONAR_USER"},
+			Value:       "admin",
+			Destination: &(config.SonarUser),
+		},
+		&cli.StringFlag{
+			Name:        "sonarpassword",
+			Usage:       "Sonarqube password to create a token when none is provided",
+			EnvVars:     []string{"SONAR_PASSWORD"},
+			Value:       "admin",
+			Destination: &(config.SonarPassword),
+		},
+	}
+}
+
+func ensureSonarToken(ctx context.Context, config *settings.Config) error {
+	if config.SonarKey != "" {
+		return nil
+	}
+
+	client := sonar.NewClientWithPassword(config.SonarURL, config.SonarUser, config.SonarPassword)
+	token, err := client.GenerateToken(ctx, uuid.NewString())
+	if err != nil {
+		return fmt.Errorf("token not provided, fail to create one with user %s: %w", config.SonarUser, err)
+	}
+	config.SonarKey = token
+
+	return nil
+}
+
+func applyStrategyFlags(c *cli.Context, config *settings.Config) error {
+	config.Languages = c.StringSlice("languages")
+	config.Bots = c.StringSlice("bots")
+
+	err := strategy.Validate(*config)
+	if err != nil {
+		return err
+	}
+
+	_, err = git.NewLanguageSet(config.Languages)
+	if err != nil {
+		return err
+	}
+
+	_, err = git.NewBotDetector(config.Bots)
+	if err != nil {
+		return err
+	}
+
+	err = strategy.ValidateNewcomers(*config)
+	if err != nil {
+		return err
+	}
+
+	return git.ValidateHistory(config.History)
+}
+
+func applySonarPropertyFlags(c *cli.Context, config *settings.Config) error {
+	config.SonarProperties = make(map[string]string)
+	config.RepositorySonarProperties = make(map[string]map[string]string)
+
+	if c.String("sonar-config") != "" {
+		sonarConfig, err := settings.LoadSonarConfig(c.String("sonar-config"))
+		if err != nil {
+			return err
+		}
+		config.SonarProperties = qualityanalyzers.MergeProperties(sonarConfig.Properties)
+		config.RepositorySonarProperties = sonarConfig.Repositories
 	}
 
-	namespace := repositoryParts[0]
-	project := repositoryParts[1]
+	flagProperties, err := settings.ParseSonarProperties(c.StringSlice("sonar-property"))
+	if err != nil {
+		return err
+	}
+	config.SonarProperties = qualityanalyzers.MergeProperties(config.SonarProperties, flagProperties)
 
-	log.Printf("starting namespace %s, project %s", namespace, project)
+	err = qualityanalyzers.ValidateProperties(config.SonarProperties)
+	if err != nil {
+		return err
+	}
 
-	var err error
+	for repository, properties := range config.RepositorySonarProperties {
+		err = qualityanalyzers.ValidateProperties(properties)
+		if err != nil {
+			return fmt.Errorf("repository %s: %w", repository, err)
+		}
+	}
+
+	return nil
+}
+
+func strategyFlags(config *settings.Config) []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:        "worktree",
+			Usage:       "Analyse local repositories in a temporary git worktree instead of a cached clone, leaving the working tree and branches untouched",
+			Destination: &(config.Worktree),
+		},
+		&cli.DurationFlag{
+			Name:        "clone-timeout",
+			Usage:       "Maximum time to clone or update a repository, 0 for no limit",
+			Value:       30 * time.Minute,
+			Destination: &(config.CloneTimeout),
+		},
+		&cli.StringFlag{
+			Name:        "strategy",
+			Usage:       fmt.Sprintf("Strategy to analyse the repositories, one of: %s", strings.Join(strategy.Names(), ", ")),
+			Value:       "PERIOD",
+			Destination: &(config.Strategy),
+		},
+		&cli.StringSliceFlag{
+			Name:  "languages",
+			Usage: fmt.Sprintf("Languages whose files count as code changes, any of: %s, or file extensions like .proto", strings.Join(git.Languages(), ", ")),
+			Value: cli.NewStringSlice(git.DefaultLanguages...),
+		},
+		&cli.StringFlag{
+			Name:        "history",
+			Usage:       fmt.Sprintf("%s to consider every commit, %s to analyse only the first parent chain, where merged branches count when they land", git.HistoryFull, git.HistoryMainline),
+			Value:       git.HistoryFull,
+			Destination: &(config.History),
+		},
+		&cli.StringFlag{
+			Name:        "alias-file",
+			Usage:       "File in .mailmap format mapping author names and emails to contributors, on top of the repository .mailmap",
+			Destination: &(config.AliasFile),
+		},
+		&cli.BoolFlag{
+			Name:        "merge-identities",
+			Usage:       "Merge authors with the same full name, and GitHub noreply emails with the matching login, into one contributor",
+			Destination: &(config.MergeIdentities),
+		},
+		&cli.StringSliceFlag{
+			Name:  "bots",
+			Usage: "Names, emails or glob patterns like *@ci.example.com of automation accounts, on top of the built-in ones like dependabot or github-actions, not counted as contributors",
+		},
+		&cli.BoolFlag{
+			Name:        "include-bots",
+			Usage:       "Count automation accounts as contributors",
+			Destination: &(config.IncludeBots),
+		},
+		&cli.StringFlag{
+			Name:        "newcomer-entrance",
+			Usage:       fmt.Sprintf("Commit that makes a contributor a newcomer, %s or %s to also count documentation and build changes", git.EntranceFirstCodeCommit, git.EntranceFirstCommit),
+			Value:       git.EntranceFirstCodeCommit,
+			Destination: &(config.NewcomerEntrance),
+		},
+		&cli.IntFlag{
+			Name:        "newcomer-min-commits",
+			Usage:       "Minimum number of commits for a contributor to count",
+			Value:       1,
+			Destination: &(config.NewcomerMinCommits),
+		},
+		&cli.IntFlag{
+			Name:        "newcomer-min-days",
+			Usage:       "Minimum number of distinct days with commits for a contributor to count",
+			Destination: &(config.NewcomerMinActiveDays),
+		},
+		&cli.IntFlag{
+			Name:        "long-term-months",
+			Usage:       "Only count the long-term contributors, still committing the given months after their first commit, 0 counts everyone",
+			Destination: &(config.LongTermMonths),
+		},
+		&cli.StringFlag{
+			Name:        "attraction",
+			Usage:       fmt.Sprintf("How newcomers are attributed to the project state that attracted them, %s for the commit their first commit was based on, %s for the start of windows of --attraction-days, %s for the last release tag before they joined", git.AttractionParent, git.AttractionDays, git.AttractionRelease),
+			Value:       git.AttractionParent,
+			Destination: &(config.Attraction),
+		},
+		&cli.IntFlag{
+			Name:        "attraction-days",
+			Usage:       "Window size when using attraction=days",
+			Value:       90,
+			Destination: &(config.AttractionDays),
+		},
+		&cli.IntFlag{
+			Name:        "interval",
+			Usage:       "When using strategy=PERIOD, set the interval in months",
+			Value:       6,
+			Destination: &(config.PeriodInterval),
+		},
+		&cli.IntFlag{
+			Name:        "batch",
+			Usage:       "Batch size when using strategy=BATCH",
+			Value:       20,
+			Destination: &(config.BatchSize),
+		},
+		&cli.StringFlag{
+			Name:        "tag-pattern",
+			Usage:       "Glob of the tags to analyse when using strategy=TAGS, e.g. 'v*', defaults to every tag",
+			Destination: &(config.TagPattern),
+		},
+	}
+}
 
-	switch config.Strategy {
-	case "ALL":
-		err = strategy.AllCommits(namespace, project, config)
-	case "PERIOD":
-		err = strategy.AnalyseByPeriod(namespace, project, config)
-	case "BATCH":
-		err = strategy.Batch(namespace, project, config)
-	case "INTEREST":
-		err = strategy.InterestNewContributor(namespace, project, config)
-	default:
-		return fmt.Errorf("unknown strategy: %s", config.Strategy)
+func runRepository(ctx context.Context, config settings.Config, repository string) error {
+	remote, err := git.ParseRemote(repository)
+	if err != nil {
+		return err
 	}
 
+	log.Printf("starting repository %s", remote)
+
+	err = strategy.Analyse(ctx, remote, config)
 	if err != nil {
 		return err
 	}
 
-	log.Printf("finished namespace %s, project %s", namespace, project)
+	log.Printf("finished repository %s", remote)
 
 	return nil
 }
+
+func planRepository(ctx context.Context, config settings.Config, repository string) ([]*strategy.PlannedAnalysis, error) {
+	remote, err := git.ParseRemote(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	gitRepo, analyses, err := strategy.Prepare(ctx, remote, config)
+	if err != nil {
+		return nil, err
+	}
+	defer gitRepo.Close()
+
+	projectKey := remote.Key()
+	plannedAnalyses := make([]*strategy.PlannedAnalysis, 0, len(analyses))
+	for _, analysis := range analyses {
+		plannedAnalyses = append(plannedAnalyses, strategy.NewPlannedAnalysis(projectKey, analysis))
+	}
+
+	return plannedAnalyses, nil
+}
+
+func collectRepository(ctx context.Context, config settings.Config, repository string, metricKeys []string) ([]*metrics.Measure, error) {
+	remote, err := git.ParseRemote(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	projectKey := remote.Key()
+	checkpoints, err := checkpoint.Open(git.GitBaseDir, projectKey, config.Strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("collecting measures of %s", projectKey)
+
+	measures, err := metrics.Collect(ctx, sonar.NewClient(config.SonarURL, config.SonarKey), projectKey, metricKeys, checkpoints.Entries())
+	if err != nil {
+		return nil, err
+	}
+
+	err = metrics.Save(git.GitBaseDir, projectKey, measures)
+	if err != nil {
+		return nil, fmt.Errorf("could not save measures: %w", err)
+	}
+
+	return measures, nil
+}
+
+func writeMeasures(outputPath string, format string, layout string, measures []*metrics.Measure) error {
+	if outputPath == "" {
+		return export.Write(os.Stdout, format, layout, measures)
+	}
+
+	// The measures go to a temporary file first, so a failed export does not
+	// leave an existing output file truncated.
+	tempPath := outputPath + ".tmp"
+	output, err := os.Create(tempPath)
+	if err != nil {
+		return fmt.Errorf("could not create output file: %w", err)
+	}
+
+	err = export.Write(output, format, layout, measures)
+	if err != nil {
+		output.Close()
+		os.Remove(tempPath)
+		return err
+	}
+
+	err = output.Close()
+	if err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("could not write output file: %w", err)
+	}
+
+	return os.Rename(tempPath, outputPath)
+}
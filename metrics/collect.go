@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/diegocsandrim/sonarminer/checkpoint"
+	"github.com/diegocsandrim/sonarminer/sonar"
+)
+
+var DefaultMetrics = []string{
+	"ncloc",
+	"complexity",
+	"cognitive_complexity",
+	"bugs",
+	"vulnerabilities",
+	"code_smells",
+	"sqale_index",
+	"duplicated_lines_density",
+}
+
+type Measure struct {
+	Project      string    `json:"project"`
+	Commit       string    `json:"commit"`
+	Version      string    `json:"projectVersion"`
+	ProjectDate  time.Time `json:"projectDate"`
+	Contributors *int      `json:"contributors"`
+	Metric       string    `json:"metric"`
+	Value        string    `json:"value"`
+}
+
+func Collect(ctx context.Context, client *sonar.Client, projectKey string, metricKeys []string, entries []*checkpoint.Entry) ([]*Measure, error) {
+	analyses, err := client.ProjectAnalyses(ctx, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not get project analyses: %w", err)
+	}
+
+	entriesByVersion := make(map[string]*checkpoint.Entry, len(entries))
+	for _, entry := range entries {
+		entriesByVersion[entry.Version] = entry
+	}
+
+	analysesByDate := make(map[int64][]*sonar.ProjectAnalysis, len(analyses))
+	for _, analysis := range analyses {
+		analysesByDate[analysis.Date.Unix()] = append(analysesByDate[analysis.Date.Unix()], analysis)
+	}
+	for date, sameDate := range analysesByDate {
+		if len(sameDate) > 1 && !sortBySubmission(sameDate, entriesByVersion) {
+			log.Printf("analyses of %s on %s have the same date and no checkpoint to order them, their measures are collected without version", projectKey, time.Unix(date, 0).UTC().Format(time.RFC3339))
+			delete(analysesByDate, date)
+		}
+	}
+
+	history, err := client.MeasuresHistory(ctx, projectKey, metricKeys)
+	if err != nil {
+		return nil, fmt.Errorf("could not get measures history: %w", err)
+	}
+
+	measures := make([]*Measure, 0)
+	for _, metricHistory := range history {
+		valuesByDate := make(map[int64]int)
+		for _, value := range metricHistory.History {
+			valuesByDate[value.Date.Unix()]++
+		}
+
+		positions := make(map[int64]int)
+		for _, value := range metricHistory.History {
+			position := positions[value.Date.Unix()]
+			positions[value.Date.Unix()]++
+			if value.Value == "" {
+				continue
+			}
+
+			measure := Measure{
+				Project:     projectKey,
+				ProjectDate: value.Date.UTC(),
+				Metric:      metricHistory.Metric,
+				Value:       value.Value,
+			}
+
+			sameDate := analysesByDate[value.Date.Unix()]
+			if len(sameDate) == valuesByDate[value.Date.Unix()] {
+				measure.Version = sameDate[position].ProjectVersion
+			}
+
+			entry, exists := entriesByVersion[measure.Version]
+			if exists {
+				contributors := entry.Contributors
+				measure.Commit = entry.Commit
+				measure.Contributors = &contributors
+			}
+
+			measures = append(measures, &measure)
+		}
+	}
+
+	sort.SliceStable(measures, func(i, j int) bool {
+		if measures[i].ProjectDate.Equal(measures[j].ProjectDate) {
+			return measures[i].Metric < measures[j].Metric
+		}
+		return measures[i].ProjectDate.Before(measures[j].ProjectDate)
+	})
+
+	return measures, nil
+}
+
+// sortBySubmission orders analyses with the same date, which were sent with
+// a day only projectDate by older versions. SonarQube keeps their measures in
+// the order they were submitted, that is the checkpoint order.
+func sortBySubmission(analyses []*sonar.ProjectAnalysis, entriesByVersion map[string]*checkpoint.Entry) bool {
+	for _, analysis := range analyses {
+		if _, exists := entriesByVersion[analysis.ProjectVersion]; !exists {
+			return false
+		}
+	}
+
+	sort.SliceStable(analyses, func(i, j int) bool {
+		return entriesByVersion[analyses[i].ProjectVersion].AnalysedAt.Before(entriesByVersion[analyses[j].ProjectVersion].AnalysedAt)
+	})
+	return true
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/diegocsandrim/sonarminer/checkpoint"
+	"github.com/diegocsandrim/sonarminer/sonar"
+)
+
+const distinctDatesHistory = `{"date":"2021-01-10T10:00:00+0000","value":"10"},{"date":"2021-01-10T10:00:01+0000","value":"20"}`
+
+func newSonarServer(t *testing.T, analyses string, history string) *sonar.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/project_analyses/search":
+			w.Write([]byte(`{"paging":{"pageIndex":1,"pageSize":500,"total":2},"analyses":` + analyses + `}`))
+		case "/api/measures/search_history":
+			w.Write([]byte(`{"paging":{"pageIndex":1,"pageSize":1000,"total":1},"measures":[{"metric":"ncloc","history":[` +
+				history + `]}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return sonar.NewClient(server.URL, "token")
+}
+
+func TestCollectMatchesAnalysesOnTheSameDay(t *testing.T) {
+	client := newSonarServer(t, `[{"key":"a1","date":"2021-01-10T10:00:00+0000","projectVersion":"v1"},{"key":"a2","date":"2021-01-10T10:00:01+0000","projectVersion":"v2"}]`, distinctDatesHistory)
+	entries := []*checkpoint.Entry{
+		{Commit: "c1", Version: "v1", Contributors: 1},
+		{Commit: "c2", Version: "v2", Contributors: 2},
+	}
+
+	measures, err := Collect(context.Background(), client, "project", []string{"ncloc"}, entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(measures) != 2 || measures[0].Commit != "c1" || measures[1].Commit != "c2" {
+		t.Errorf("measures are not matched to their own analysis: %+v, %+v", measures[0], measures[1])
+	}
+}
+
+func TestCollectOrdersLegacyAnalysesOnTheSameDate(t *testing.T) {
+	// Older versions sent the day only, SonarQube lists the newest analysis first.
+	client := newSonarServer(t,
+		`[{"key":"a2","date":"2021-01-10T00:00:00+0000","projectVersion":"v2"},{"key":"a1","date":"2021-01-10T00:00:00+0000","projectVersion":"v1"}]`,
+		`{"date":"2021-01-10T00:00:00+0000","value":"10"},{"date":"2021-01-10T00:00:00+0000","value":"20"}`)
+	entries := []*checkpoint.Entry{
+		{Commit: "c2", Version: "v2", Contributors: 2, AnalysedAt: time.Date(2021, 3, 1, 10, 5, 0, 0, time.UTC)},
+		{Commit: "c1", Version: "v1", Contributors: 1, AnalysedAt: time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)},
+	}
+
+	measures, err := Collect(context.Background(), client, "project", []string{"ncloc"}, entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(measures) != 2 || measures[0].Commit != "c1" || measures[0].Value != "10" || measures[1].Commit != "c2" || measures[1].Value != "20" {
+		t.Errorf("measures are not matched in submission order: %+v, %+v", measures[0], measures[1])
+	}
+}
+
+func TestCollectKeepsLegacyAnalysesWithoutCheckpoint(t *testing.T) {
+	client := newSonarServer(t,
+		`[{"key":"a2","date":"2021-01-10T00:00:00+0000","projectVersion":"v2"},{"key":"a1","date":"2021-01-10T00:00:00+0000","projectVersion":"v1"}]`,
+		`{"date":"2021-01-10T00:00:00+0000","value":"10"},{"date":"2021-01-10T00:00:00+0000","value":"20"}`)
+
+	measures, err := Collect(context.Background(), client, "project", []string{"ncloc"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(measures) != 2 || measures[0].Version != "" || measures[1].Version != "" {
+		t.Errorf("measures of analyses that cannot be told apart got a version: %+v, %+v", measures[0], measures[1])
+	}
+}
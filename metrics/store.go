@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/diegocsandrim/sonarminer/checkpoint"
+)
+
+func Save(baseDir string, projectKey string, measures []*Measure) error {
+	storePath := storeFile(baseDir, projectKey)
+	err := os.MkdirAll(path.Dir(storePath), 0775)
+	if err != nil {
+		return fmt.Errorf("fail to create measures directory: %w", err)
+	}
+
+	tempPath := storePath + ".tmp"
+	file, err := os.Create(tempPath)
+	if err != nil {
+		return fmt.Errorf("fail to create measures file '%s': %w", tempPath, err)
+	}
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, measure := range measures {
+		err = encoder.Encode(measure)
+		if err != nil {
+			file.Close()
+			return err
+		}
+	}
+
+	err = writer.Flush()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tempPath, storePath)
+	if err != nil {
+		return err
+	}
+
+	legacyPath := legacyStoreFile(baseDir, projectKey)
+	err = os.Remove(legacyPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("fail to remove measures file '%s': %w", legacyPath, err)
+	}
+	return nil
+}
+
+func Load(baseDir string, projectKeys []string) ([]*Measure, error) {
+	storePaths := make([]string, 0, len(projectKeys))
+	for _, projectKey := range projectKeys {
+		storePath := storeFile(baseDir, projectKey)
+		if _, err := os.Stat(storePath); errors.Is(err, os.ErrNotExist) {
+			if _, err := os.Stat(legacyStoreFile(baseDir, projectKey)); err == nil {
+				storePath = legacyStoreFile(baseDir, projectKey)
+			}
+		}
+		storePaths = append(storePaths, storePath)
+	}
+
+	if len(projectKeys) == 0 {
+		var err error
+		storePaths, err = filepath.Glob(path.Join(baseDir, "measures", "*.jsonl"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	measures := make([]*Measure, 0)
+	for _, storePath := range storePaths {
+		file, err := os.Open(storePath)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("no measures collected yet, file not found: '%s'", storePath)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		decoder := json.NewDecoder(file)
+		for decoder.More() {
+			measure := Measure{}
+			err = decoder.Decode(&measure)
+			if err != nil {
+				file.Close()
+				return nil, fmt.Errorf("measures file '%s' is in a bad format: %w", storePath, err)
+			}
+			measures = append(measures, &measure)
+		}
+		file.Close()
+	}
+
+	return measures, nil
+}
+
+func storeFile(baseDir string, projectKey string) string {
+	return path.Join(baseDir, "measures", checkpoint.FileName(projectKey)+".jsonl")
+}
+
+func legacyStoreFile(baseDir string, projectKey string) string {
+	return path.Join(baseDir, "measures", checkpoint.LegacyFileName(projectKey)+".jsonl")
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/diegocsandrim/sonarminer/checkpoint"
+)
+
+func TestProjectsDoNotShareMeasures(t *testing.T) {
+	baseDir := t.TempDir()
+
+	err := Save(baseDir, "a_b:c", []*Measure{{Project: "a_b:c", Metric: "ncloc", Value: "10"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = Save(baseDir, "a:b_c", []*Measure{{Project: "a:b_c", Metric: "ncloc", Value: "20"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	measures, err := Load(baseDir, []string{"a_b:c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(measures) != 1 || measures[0].Value != "10" {
+		t.Errorf("measures of a_b:c = %+v", measures)
+	}
+
+	measures, err = Load(baseDir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(measures) != 2 {
+		t.Errorf("loaded %d measures, want 2", len(measures))
+	}
+}
+
+func TestLoadReadsLegacyMeasures(t *testing.T) {
+	baseDir := t.TempDir()
+
+	legacyPath := path.Join(baseDir, "measures", checkpoint.LegacyFileName("owner:project")+".jsonl")
+	err := os.MkdirAll(path.Dir(legacyPath), 0775)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(legacyPath, []byte(`{"project":"owner:project","metric":"ncloc","value":"10"}`+"\n"), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	measures, err := Load(baseDir, []string{"owner:project"})
+	if err != nil || len(measures) != 1 {
+		t.Fatalf("Load = %+v, %v", measures, err)
+	}
+
+	err = Save(baseDir, "owner:project", measures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(legacyPath); !os.IsNotExist(err) {
+		t.Errorf("legacy measures file was kept: %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package qualityanalyzers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"syscall"
+
+	"github.com/diegocsandrim/sonarminer/docker"
+	"github.com/google/uuid"
+)
+
+const (
+	containerNamePrefix = "sonarminer-"
+	pidLabel            = "sonarminer.pid"
+	hostLabel           = "sonarminer.host"
+)
+
+func newContainerName() string {
+	return containerNamePrefix + uuid.NewString()
+}
+
+func containerLabels() map[string]string {
+	hostname, _ := os.Hostname()
+	return map[string]string{
+		pidLabel:  strconv.Itoa(os.Getpid()),
+		hostLabel: hostname,
+	}
+}
+
+func RemoveOwnContainers(ctx context.Context) error {
+	return removeContainers(ctx, func(pid int, host string) bool {
+		return pid == os.Getpid()
+	})
+}
+
+func RemoveOrphanContainers(ctx context.Context) error {
+	hostname, _ := os.Hostname()
+	return removeContainers(ctx, func(pid int, host string) bool {
+		return host == hostname && !processExists(pid)
+	})
+}
+
+func removeContainers(ctx context.Context, shouldRemove func(pid int, host string) bool) error {
+	dockerClient, err := docker.NewClientFromEnv()
+	if err != nil {
+		return err
+	}
+
+	containers, err := dockerClient.ListContainers(ctx, pidLabel)
+	if err != nil {
+		return fmt.Errorf("fail to list sonarminer containers: %w", err)
+	}
+
+	for _, container := range containers {
+		pid, err := strconv.Atoi(container.Labels[pidLabel])
+		if err != nil {
+			continue
+		}
+
+		if !shouldRemove(pid, container.Labels[hostLabel]) {
+			continue
+		}
+
+		log.Printf("removing sonarminer container %s", container.Id)
+		err = dockerClient.RemoveContainer(ctx, container.Id, true)
+		if err != nil && !docker.IsNotFound(err) {
+			return fmt.Errorf("fail to remove container %s: %w", container.Id, err)
+		}
+	}
+
+	return nil
+}
+
+func processExists(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
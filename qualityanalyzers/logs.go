@@ -0,0 +1,65 @@
+package qualityanalyzers
+
+import (
+	"bytes"
+	"log"
+	"strings"
+)
+
+const (
+	scannerLogTailLines = 50
+	scannerLogMaxLine   = 64 * 1024
+)
+
+// scannerLog writes the scanner output to the log line by line as it
+// arrives, keeping only the last lines for the error message.
+type scannerLog struct {
+	prefix  string
+	partial []byte
+	tail    []string
+}
+
+func newScannerLog(prefix string) *scannerLog {
+	return &scannerLog{prefix: prefix}
+}
+
+func (l *scannerLog) Write(p []byte) (int, error) {
+	l.partial = append(l.partial, p...)
+	for {
+		i := bytes.IndexByte(l.partial, '\n')
+		if i < 0 {
+			break
+		}
+		l.line(string(l.partial[:i]))
+		l.partial = l.partial[i+1:]
+	}
+
+	// A line without end is written in pieces, the buffer must stay bounded.
+	if len(l.partial) > scannerLogMaxLine {
+		l.Flush()
+	}
+
+	return len(p), nil
+}
+
+// Flush writes the last line when the output does not end with a newline.
+func (l *scannerLog) Flush() {
+	if len(l.partial) > 0 {
+		l.line(string(l.partial))
+		l.partial = nil
+	}
+}
+
+func (l *scannerLog) Tail() string {
+	return strings.Join(l.tail, "\n")
+}
+
+func (l *scannerLog) line(line string) {
+	line = strings.TrimRight(line, "\r")
+	log.Printf("%s: %s", l.prefix, line)
+
+	l.tail = append(l.tail, line)
+	if len(l.tail) > scannerLogTailLines {
+		l.tail = l.tail[len(l.tail)-scannerLogTailLines:]
+	}
+}
@@ -0,0 +1,44 @@
+package qualityanalyzers
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestScannerLogStreamsLinesAndKeepsTheTail(t *testing.T) {
+	var output bytes.Buffer
+	log.SetOutput(&output)
+	log.SetFlags(0)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+	}()
+
+	logs := newScannerLog("owner:project")
+	logs.Write([]byte("INFO: Scanner"))
+	if output.Len() != 0 {
+		t.Errorf("an unfinished line was logged: %q", output.String())
+	}
+	logs.Write([]byte(" started\r\nINFO: "))
+	if output.String() != "owner:project: INFO: Scanner started\n" {
+		t.Errorf("log = %q", output.String())
+	}
+
+	for i := 0; i < scannerLogTailLines+10; i++ {
+		fmt.Fprintf(logs, "line %d\n", i)
+	}
+	logs.Write([]byte("ERROR: failed"))
+	logs.Flush()
+
+	tail := strings.Split(logs.Tail(), "\n")
+	if len(tail) != scannerLogTailLines || tail[len(tail)-1] != "ERROR: failed" || tail[0] != "line 11" {
+		t.Errorf("tail has %d lines, from %q to %q", len(tail), tail[0], tail[len(tail)-1])
+	}
+	if !strings.HasSuffix(output.String(), "owner:project: ERROR: failed\n") {
+		t.Errorf("the last line was not logged: %q", output.String())
+	}
+}
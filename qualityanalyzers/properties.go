@@ -0,0 +1,82 @@
+package qualityanalyzers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const defaultExclusions = "**/vendor/**,**/node_modules/**,**/third_party/**,**/*.pb.go,**/*generated*.go,**/*_gen.go,**/*.min.js"
+
+var reservedProperties = map[string]interface{}{
+	"sonar.host.url":              nil,
+	"sonar.login":                 nil,
+	"sonar.projectKey":            nil,
+	"sonar.projectBaseDir":        nil,
+	"sonar.projectVersion":        nil,
+	"sonar.projectDate":           nil,
+	"sonar.analysis.contributors": nil,
+}
+
+func DefaultProperties(extensions []string) map[string]string {
+	inclusions := make([]string, 0, len(extensions))
+	for _, extension := range extensions {
+		inclusions = append(inclusions, "**/*"+extension)
+	}
+
+	return map[string]string{
+		"sonar.scm.disabled": "True",
+		"sonar.inclusions":   strings.Join(inclusions, ","),
+		"sonar.exclusions":   defaultExclusions,
+	}
+}
+
+// WithDefaults applies the properties over the default ones. The exclusions
+// are added to the default exclusions, so vendored and generated code stay
+// excluded.
+func WithDefaults(extensions []string, properties map[string]string) map[string]string {
+	defaults := DefaultProperties(extensions)
+	merged := MergeProperties(defaults, properties)
+
+	if exclusions := strings.Trim(properties["sonar.exclusions"], ", "); exclusions != "" {
+		merged["sonar.exclusions"] = defaults["sonar.exclusions"] + "," + exclusions
+	}
+
+	return merged
+}
+
+func ValidateProperties(properties map[string]string) error {
+	for key := range properties {
+		if !strings.HasPrefix(key, "sonar.") {
+			return fmt.Errorf("sonar property must start with 'sonar.': '%s'", key)
+		}
+		if _, reserved := reservedProperties[key]; reserved {
+			return fmt.Errorf("sonar property is set by sonarminer and cannot be overridden: '%s'", key)
+		}
+	}
+	return nil
+}
+
+func MergeProperties(properties ...map[string]string) map[string]string {
+	merged := make(map[string]string)
+	for _, p := range properties {
+		for key, value := range p {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+func propertiesArgs(properties map[string]string) []string {
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	args := make([]string, 0, len(keys)*2)
+	for _, key := range keys {
+		args = append(args, "-D", key+"="+properties[key])
+	}
+	return args
+}
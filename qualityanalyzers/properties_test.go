@@ -0,0 +1,21 @@
+package qualityanalyzers
+
+import "testing"
+
+func TestWithDefaultsKeepsDefaultExclusions(t *testing.T) {
+	properties := WithDefaults([]string{".go"}, map[string]string{
+		"sonar.exclusions": "**/testdata/**",
+		"sonar.tests":      ".",
+	})
+
+	if want := defaultExclusions + ",**/testdata/**"; properties["sonar.exclusions"] != want {
+		t.Errorf("sonar.exclusions = %q, want %q", properties["sonar.exclusions"], want)
+	}
+	if properties["sonar.tests"] != "." || properties["sonar.inclusions"] != "**/*.go" {
+		t.Errorf("properties = %v, want the user and default properties", properties)
+	}
+
+	if properties := WithDefaults([]string{".go"}, nil); properties["sonar.exclusions"] != defaultExclusions {
+		t.Errorf("sonar.exclusions = %q, want the defaults", properties["sonar.exclusions"])
+	}
+}
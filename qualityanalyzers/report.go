@@ -0,0 +1,27 @@
+package qualityanalyzers
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+func readReportTask(projectDir string) (map[string]string, error) {
+	reportPath := path.Join(projectDir, ".scannerwork", "report-task.txt")
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read scanner report '%s': %w", reportPath, err)
+	}
+
+	report := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		report[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+
+	return report, nil
+}
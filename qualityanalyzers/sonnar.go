@@ -1,12 +1,25 @@
 package qualityanalyzers
 
 import (
+	"context"
 	"fmt"
 	"log"
-	"strings"
+	"os"
+	"path"
 	"time"
 
 	"github.com/diegocsandrim/sonarminer/cmd"
+	"github.com/diegocsandrim/sonarminer/docker"
+	"github.com/diegocsandrim/sonarminer/sonar"
+)
+
+const scannerImage = "sonarsource/sonar-scanner-cli:4.7"
+
+const (
+	ceTaskPollInterval = 2 * time.Second
+	ceTaskTimeout      = 30 * time.Minute
+	scannerStopTimeout = 10 * time.Second
+	cleanupTimeout     = 2 * time.Minute
 )
 
 type Sonnar struct {
@@ -14,80 +27,177 @@ type Sonnar struct {
 	sonarLogin    string
 	sonnarHostUrl string
 	projectDir    string
+	properties    map[string]string
+	containerId   string
 	cmdFactory    *cmd.CmdFactory
+	docker        *docker.Client
+	sonar         *sonar.Client
+}
+
+type ScannerError struct {
+	ExitCode int
+	Logs     string
+}
+
+func (e *ScannerError) Error() string {
+	return fmt.Sprintf("sonar analyser has failed with exit code %d: %s", e.ExitCode, e.Logs)
 }
 
-func CreateSonnarAnalyser(projectKey string, sonarLogin string, sonnarHostUrl string, projectDir string) (*Sonnar, error) {
+func CreateSonnarAnalyser(ctx context.Context, projectKey string, sonarLogin string, sonnarHostUrl string, projectDir string, properties map[string]string) (*Sonnar, error) {
+	err := ValidateProperties(properties)
+	if err != nil {
+		return nil, err
+	}
+
+	dockerClient, err := docker.NewClientFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("could not create docker client: %w", err)
+	}
+
 	analyser := Sonnar{
 		projectKey:    projectKey,
 		sonarLogin:    sonarLogin,
 		sonnarHostUrl: sonnarHostUrl,
 		projectDir:    projectDir,
+		properties:    properties,
 		cmdFactory:    cmd.NewCmdFactory(projectDir),
+		docker:        dockerClient,
+		sonar:         sonar.NewClient(sonnarHostUrl, sonarLogin),
+	}
+
+	err = analyser.pullImage(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	return &analyser, nil
 }
 
-func (s *Sonnar) Run(projectVersion string, date time.Time, attractedContributors int) error {
-	projectDate := date.UTC().Format("2006-01-02")
-
-	output, err := s.cmdFactory.ExecF(`
-	rm -f ./sonar-project.properties && \
-	docker run --name sonar-scanner --network host -dit -v %s:/root/src -v /tmp/scanner-cache:/root/.sonar/cache sonarsource/sonar-scanner-cli:4.7 \
-	-D sonar.scm.disabled=True \
-    -D sonar.host.url=%s \
-    -D sonar.projectKey=%s \
-    -D sonar.projectBaseDir=/root/src \
-	-D sonar.login=%s \
-	-D sonar.projectVersion=%s \
-	-D sonar.projectDate=%s \
-	-D sonar.analysis.contributors=%d \
-	-D sonar.exclusions=**/vendor/**,**/*.pb.go,**/*generated*.go,**/*.cs,**/*.css,**/*.less,**/*.scss,**/*as,**/*.html,**/*.xhtml,**/*.cshtml,**/*.vbhtml,**/*.aspx,**/*.ascx,**/*.rhtml,**/*.erb,**/*.shtm,**/*.shtml,**/*.jsp,**/*.jspf,**/*.jspx,**/*.java,**/*.jav,**/*.js,**/*.jsx,**/*.vue,**/*.kt,**/*php,**/*php3,**/*php4,**/*php5,**/*phtml,**/*inc,**/*py,**/*.rb,**/*.scala,**/*.ts,**/*.tsx,**/*.vb,**/*.xml,**/*.xsd,**/*.xsl,**/*_gen.go
-	`, s.projectDir, s.sonnarHostUrl, s.projectKey, s.sonarLogin, projectVersion, projectDate, attractedContributors)
+func (s *Sonnar) pullImage(ctx context.Context) error {
+	exists, err := s.docker.ImageExists(ctx, scannerImage)
+	if err != nil {
+		return fmt.Errorf("could not inspect scanner image: %w", err)
+	}
+	if exists {
+		return nil
+	}
+
+	log.Printf("pulling scanner image %s...", scannerImage)
+	err = s.docker.PullImage(ctx, scannerImage)
+	if err != nil {
+		return fmt.Errorf("could not pull scanner image: %w", err)
+	}
+	return nil
+}
+
+func (s *Sonnar) Run(ctx context.Context, projectVersion string, date time.Time, attractedContributors int) error {
+	// With the time, analyses on the same day keep distinct dates, which is
+	// how their measures are told apart when collected.
+	projectDate := date.UTC().Format("2006-01-02T15:04:05-0700")
 
+	err := os.Remove(path.Join(s.projectDir, "sonar-project.properties"))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove sonar-project.properties: %w", err)
+	}
+
+	s.containerId, err = s.docker.CreateContainer(ctx, newContainerName(), &docker.ContainerConfig{
+		Image: scannerImage,
+		Cmd: append([]string{
+			"-D", "sonar.host.url=" + s.sonnarHostUrl,
+			"-D", "sonar.projectKey=" + s.projectKey,
+			"-D", "sonar.projectBaseDir=/root/src",
+			"-D", "sonar.login=" + s.sonarLogin,
+			"-D", "sonar.projectVersion=" + projectVersion,
+			"-D", "sonar.projectDate=" + projectDate,
+			"-D", fmt.Sprintf("sonar.analysis.contributors=%d", attractedContributors),
+		}, propertiesArgs(s.properties)...),
+		Labels: containerLabels(),
+		HostConfig: &docker.HostConfig{
+			NetworkMode: "host",
+			Binds: []string{
+				s.projectDir + ":/root/src",
+				"/tmp/scanner-cache:/root/.sonar/cache",
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create scanner: %w", err)
+	}
+
+	defer s.removeScanner(ctx)
+
+	err = s.docker.StartContainer(ctx, s.containerId)
 	if err != nil {
 		return fmt.Errorf("failed to start scanner: %w", err)
 	}
 
-	output, err = s.cmdFactory.ExecF("docker wait sonar-scanner")
+	logs := newScannerLog(s.projectKey)
+	logsDone := make(chan error, 1)
+	go func() {
+		logsDone <- s.docker.ContainerLogs(ctx, s.containerId, true, logs, logs)
+	}()
+
+	exitCode, err := s.docker.WaitContainer(ctx, s.containerId)
+	if ctx.Err() != nil {
+		return fmt.Errorf("scanner was stopped: %w", ctx.Err())
+	}
 	if err != nil {
 		return fmt.Errorf("failed waiting scanner to finish: %w", err)
 	}
-	defer func() {
-		output, err = s.cmdFactory.ExecF("docker rm sonar-scanner && git restore ./sonar-project.properties || true")
-		if err != nil {
-			log.Printf("failed to remove scanner: %s", err.Error())
-			log.Printf("output: %s", output)
+
+	logsErr := <-logsDone
+	logs.Flush()
+	if exitCode != 0 {
+		if logsErr != nil {
+			return fmt.Errorf("%s: %w", "sonar analyser has failed, but we could not get the logs", logsErr)
 		}
-	}()
+		return &ScannerError{ExitCode: exitCode, Logs: logs.Tail()}
+	}
+
+	return s.waitCeTask(ctx)
+}
 
-	exitsCode := strings.Split(output, "\n")[0]
-	if exitsCode != "0" {
-		logs, err := s.cmdFactory.ExecF("docker logs sonar-scanner")
+// removeScanner uses its own context, the scanner must be removed even when
+// the analysis was canceled. A canceled scanner is stopped first, so it can
+// exit before being killed.
+func (s *Sonnar) removeScanner(ctx context.Context) {
+	cleanupCtx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+
+	if ctx.Err() != nil {
+		log.Printf("stopping scanner %s", s.containerId)
+		err := s.docker.StopContainer(cleanupCtx, s.containerId, scannerStopTimeout)
 		if err != nil {
-			return fmt.Errorf("%s: %s: %w", "sonar analyser has failed, but we could not get the logs", output, err)
+			log.Printf("failed to stop scanner: %s", err.Error())
 		}
+	}
 
-		return fmt.Errorf("%s: %s", "sonar analyser has failed", logs)
+	err := s.docker.RemoveContainer(cleanupCtx, s.containerId, true)
+	if err != nil {
+		log.Printf("failed to remove scanner: %s", err.Error())
 	}
 
-	return err
+	// Most repositories do not track the file, then there is nothing to restore.
+	_, _ = s.cmdFactory.Run(cleanupCtx, "git", "restore", "--", "sonar-project.properties")
 }
 
-func FormatProjectKey(parts ...string) string {
-	return strings.Join(parts, ":")
-}
+func (s *Sonnar) waitCeTask(ctx context.Context) error {
+	report, err := readReportTask(s.projectDir)
+	if err != nil {
+		return err
+	}
+
+	taskId := report["ceTaskId"]
+	if taskId == "" {
+		return fmt.Errorf("scanner report has no ceTaskId")
+	}
 
-func CollectMetrics() {
-	// TODO: use the SQL to colect the metrics after all analysis has run
-	// SELECT projects.name, metrics.name, project_measures.value
-	// FROM
-	// 	project_measures
-	// 	inner join metrics on project_measures.metric_id=metrics.id
-	// 	inner join projects on project_measures.component_uuid = projects.uuid
-	// where metrics.name like 'cognitive_complexity'
-	// order by project_measures.id;
+	_, err = s.sonar.WaitCeTask(ctx, taskId, ceTaskPollInterval, ceTaskTimeout)
+	if err != nil {
+		return fmt.Errorf("sonarqube failed to process the analysis: %w", err)
+	}
+
+	return nil
 }
 
 func (s *Sonnar) Close() {
@@ -95,11 +205,41 @@ func (s *Sonnar) Close() {
 }
 
 func (s *Sonnar) cleanTempDirs() {
-	output, err := s.cmdFactory.ExecF(`docker run -i --rm --network host -v %s:/root/src --entrypoint='' sonarsource/sonar-scanner-cli:4.7 \
-	rm -rf /root/src/.scannerwork \
-	`, s.projectDir)
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+
+	containerId, err := s.docker.CreateContainer(ctx, newContainerName(), &docker.ContainerConfig{
+		Image:      scannerImage,
+		Entrypoint: []string{"rm", "-rf", "/root/src/.scannerwork"},
+		Labels:     containerLabels(),
+		HostConfig: &docker.HostConfig{
+			NetworkMode: "host",
+			Binds:       []string{s.projectDir + ":/root/src"},
+		},
+	})
+	if err != nil {
+		log.Printf("failed to cleanup scanner: %s", err.Error())
+		return
+	}
+	defer func() {
+		err := s.docker.RemoveContainer(ctx, containerId, true)
+		if err != nil {
+			log.Printf("failed to remove cleanup container: %s", err.Error())
+		}
+	}()
+
+	err = s.docker.StartContainer(ctx, containerId)
+	if err != nil {
+		log.Printf("failed to cleanup scanner: %s", err.Error())
+		return
+	}
+
+	exitCode, err := s.docker.WaitContainer(ctx, containerId)
 	if err != nil {
-		log.Printf("failed to cleanup scanner: %w: %s", err, output)
+		log.Printf("failed to cleanup scanner: %s", err.Error())
 		return
 	}
+	if exitCode != 0 {
+		log.Printf("failed to cleanup scanner, exit code: %d", exitCode)
+	}
 }
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/diegocsandrim/sonarminer/git"
+	"github.com/diegocsandrim/sonarminer/settings"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	repositoryStatusSucceeded = "succeeded"
+	repositoryStatusFailed    = "failed"
+	repositoryStatusSkipped   = "skipped"
+)
+
+type repositoryJob struct {
+	repository string
+	config     settings.Config
+}
+
+type repositoryResult struct {
+	repository string
+	status     string
+	duration   time.Duration
+	err        error
+}
+
+func newRepositoryJobs(config settings.Config, repositories []string) []*repositoryJob {
+	jobs := make([]*repositoryJob, 0, len(repositories))
+	for _, repository := range uniqueRepositories(repositories) {
+		jobs = append(jobs, &repositoryJob{repository: repository, config: config})
+	}
+	return jobs
+}
+
+func runRepositories(ctx context.Context, repositoryJobs []*repositoryJob, parallel int, keepGoing bool) []*repositoryResult {
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	results := make([]*repositoryResult, len(repositoryJobs))
+	jobs := make(chan int)
+
+	var mutex sync.Mutex
+	failed := false
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < parallel; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				repository := repositoryJobs[i].repository
+
+				mutex.Lock()
+				skip := (failed && !keepGoing) || ctx.Err() != nil
+				mutex.Unlock()
+
+				if skip {
+					results[i] = &repositoryResult{repository: repository, status: repositoryStatusSkipped}
+					continue
+				}
+
+				start := time.Now()
+				err := runRepository(ctx, repositoryJobs[i].config, repository)
+				result := repositoryResult{
+					repository: repository,
+					status:     repositoryStatusSucceeded,
+					duration:   time.Since(start),
+					err:        err,
+				}
+				if err != nil {
+					log.Printf("failed repository %s: %s", repository, err.Error())
+					result.status = repositoryStatusFailed
+
+					mutex.Lock()
+					failed = true
+					mutex.Unlock()
+				}
+				results[i] = &result
+			}
+		}()
+	}
+
+	for i := range repositoryJobs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+func writeSummary(w io.Writer, results []*repositoryResult) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "REPOSITORY\tSTATUS\tDURATION\tERROR")
+	for _, result := range results {
+		errorMessage := ""
+		if result.err != nil {
+			errorMessage = strings.SplitN(result.err.Error(), "\n", 2)[0]
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", result.repository, result.status, result.duration.Round(time.Second), errorMessage)
+	}
+	return table.Flush()
+}
+
+func countFailures(results []*repositoryResult) int {
+	failures := 0
+	for _, result := range results {
+		if result.status != repositoryStatusSucceeded {
+			failures++
+		}
+	}
+	return failures
+}
+
+func reposFileFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "repos-file",
+		Usage: "File with one repository per line, text after a # is a comment, used in addition to the arguments",
+	}
+}
+
+func repositoryArgs(c *cli.Context) ([]string, error) {
+	repositories := c.Args().Slice()
+	if c.String("repos-file") != "" {
+		fileRepositories, err := settings.LoadRepositoriesFile(c.String("repos-file"))
+		if err != nil {
+			return nil, err
+		}
+		repositories = append(repositories, fileRepositories...)
+	}
+
+	return uniqueRepositories(repositories), nil
+}
+
+// uniqueRepositories drops the repositories that point to the same remote as
+// an earlier one, like owner/name and https://github.com/owner/name.git, as
+// they would share the cache directory and the SonarQube project.
+func uniqueRepositories(repositories []string) []string {
+	unique := make([]string, 0, len(repositories))
+	seen := make(map[string]string, len(repositories))
+	reported := make(map[string]interface{})
+	for _, repository := range repositories {
+		key := repositoryKey(repository)
+		if first, exists := seen[key]; exists {
+			if _, exists := reported[repository]; !exists && first != repository {
+				log.Printf("skipping repository %s, it is the same as %s", repository, first)
+				reported[repository] = nil
+			}
+			continue
+		}
+		seen[key] = repository
+		unique = append(unique, repository)
+	}
+	return unique
+}
+
+// repositoryKey identifies the remote of a repository argument, the argument
+// itself is kept when it cannot be parsed, so the job reports the error.
+func repositoryKey(repository string) string {
+	remote, err := git.ParseRemote(repository)
+	if err != nil {
+		return repository
+	}
+	return remote.Key()
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUniqueRepositories(t *testing.T) {
+	repositories := []string{
+		"a/b",
+		"https://github.com/a/b.git",
+		"/srv/x",
+		"file:///srv/x",
+		"a/b",
+		"a/c",
+		"not a remote::",
+	}
+
+	unique := uniqueRepositories(repositories)
+	want := []string{"a/b", "/srv/x", "a/c", "not a remote::"}
+	if !reflect.DeepEqual(unique, want) {
+		t.Errorf("uniqueRepositories = %q, want %q", unique, want)
+	}
+}
@@ -0,0 +1,361 @@
+package settings
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var envReference = regexp.MustCompile(`\$\{[A-Za-z_][A-Za-z0-9_]*\}`)
+
+type Campaign struct {
+	Sonar        CampaignSonar         `yaml:"sonar"`
+	Parallel     int                   `yaml:"parallel"`
+	KeepGoing    bool                  `yaml:"keepGoing"`
+	Resume       bool                  `yaml:"resume"`
+	Defaults     CampaignOptions       `yaml:"defaults"`
+	Repositories []*CampaignRepository `yaml:"repositories"`
+	// RepositoriesFile lists more repositories, one per line, that use the
+	// default options.
+	RepositoriesFile string         `yaml:"repositoriesFile"`
+	Output           CampaignOutput `yaml:"output"`
+}
+
+type CampaignSonar struct {
+	URL      string `yaml:"url"`
+	Token    string `yaml:"token"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+}
+
+type CampaignOptions struct {
+	Strategy   string            `yaml:"strategy"`
+	Interval   int               `yaml:"interval"`
+	Batch      int               `yaml:"batch"`
+	TagPattern string            `yaml:"tagPattern"`
+	Languages  []string          `yaml:"languages"`
+	History    string            `yaml:"history"`
+	Worktree   *bool             `yaml:"worktree"`
+	Exclusions []string          `yaml:"exclusions"`
+	Properties map[string]string `yaml:"properties"`
+
+	// AliasFile is a .mailmap format file, relative to the campaign file.
+	AliasFile       string `yaml:"aliasFile"`
+	MergeIdentities *bool  `yaml:"mergeIdentities"`
+
+	Bots        []string `yaml:"bots"`
+	IncludeBots *bool    `yaml:"includeBots"`
+
+	Newcomers      CampaignNewcomers `yaml:"newcomers"`
+	Attraction     string            `yaml:"attraction"`
+	AttractionDays int               `yaml:"attractionDays"`
+
+	CloneTimeout time.Duration `yaml:"cloneTimeout"`
+	ScanTimeout  time.Duration `yaml:"scanTimeout"`
+}
+
+type CampaignNewcomers struct {
+	Entrance       string `yaml:"entrance"`
+	MinCommits     int    `yaml:"minCommits"`
+	MinActiveDays  int    `yaml:"minActiveDays"`
+	LongTermMonths int    `yaml:"longTermMonths"`
+}
+
+type CampaignRepository struct {
+	Repository      string `yaml:"repository"`
+	CampaignOptions `yaml:",inline"`
+}
+
+type CampaignOutput struct {
+	Dir     string   `yaml:"dir"`
+	Format  string   `yaml:"format"`
+	Layout  string   `yaml:"layout"`
+	Metrics []string `yaml:"metrics"`
+}
+
+// UnmarshalYAML accepts a plain repository name as a shorthand for an entry
+// without options.
+func (r *CampaignRepository) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		r.Repository = node.Value
+		return nil
+	}
+
+	// node.Decode does not inherit the strict mode of the campaign decoder.
+	err := checkKnownFields(node, reflect.TypeOf(*r))
+	if err != nil {
+		return err
+	}
+
+	type plain CampaignRepository
+	return node.Decode((*plain)(r))
+}
+
+func checkKnownFields(node *yaml.Node, structType reflect.Type) error {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	fields := yamlFields(structType)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		fieldType, exists := fields[key.Value]
+		if !exists {
+			return fmt.Errorf("line %d: field %s not found in type %s", key.Line, key.Value, structType)
+		}
+
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct {
+			err := checkKnownFields(node.Content[i+1], fieldType)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func yamlFields(structType reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		tag := strings.SplitN(field.Tag.Get("yaml"), ",", 2)
+		if len(tag) == 2 && tag[1] == "inline" {
+			for name, fieldType := range yamlFields(field.Type) {
+				fields[name] = fieldType
+			}
+			continue
+		}
+
+		name := tag[0]
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+func LoadCampaign(path string) (*Campaign, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read campaign file '%s': %w", path, err)
+	}
+
+	campaign := Campaign{
+		Sonar: CampaignSonar{
+			URL:      "http://127.0.0.1:9000",
+			Token:    os.Getenv("SONAR_TOKEN"),
+			User:     "admin",
+			Password: "admin",
+		},
+		Parallel: 1,
+		Defaults: CampaignOptions{
+			Strategy:     "PERIOD",
+			Interval:     6,
+			Batch:        20,
+			CloneTimeout: 30 * time.Minute,
+			ScanTimeout:  time.Hour,
+
+			AttractionDays: 90,
+		},
+	}
+
+	decoder := yaml.NewDecoder(strings.NewReader(expandEnv(string(data))))
+	decoder.KnownFields(true)
+	err = decoder.Decode(&campaign)
+	if err != nil {
+		return nil, fmt.Errorf("campaign file '%s' is in a bad format: %w", path, err)
+	}
+
+	// Paths are made absolute, a relative ./project joined to the campaign
+	// directory would read as a namespace/project GitHub repository.
+	baseDir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("fail to resolve campaign file directory '%s': %w", path, err)
+	}
+	if campaign.RepositoriesFile != "" {
+		repositoriesFile := resolveFile(baseDir, campaign.RepositoriesFile)
+		repositories, err := LoadRepositoriesFile(repositoriesFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, repository := range repositories {
+			campaign.Repositories = append(campaign.Repositories, &CampaignRepository{
+				Repository: resolvePath(filepath.Dir(repositoriesFile), repository),
+			})
+		}
+	}
+
+	if len(campaign.Repositories) == 0 {
+		return nil, fmt.Errorf("campaign file '%s' has no repositories", path)
+	}
+
+	for i, repository := range campaign.Repositories {
+		if repository.Repository == "" {
+			return nil, fmt.Errorf("campaign file '%s': repository %d has no name", path, i+1)
+		}
+		repository.Repository = resolvePath(baseDir, repository.Repository)
+	}
+	if campaign.Defaults.AliasFile != "" {
+		campaign.Defaults.AliasFile = resolveFile(baseDir, campaign.Defaults.AliasFile)
+	}
+	for _, repository := range campaign.Repositories {
+		if repository.AliasFile != "" {
+			repository.AliasFile = resolveFile(baseDir, repository.AliasFile)
+		}
+	}
+	if campaign.Output.Dir != "" {
+		campaign.Output.Dir = resolveFile(baseDir, campaign.Output.Dir)
+	}
+
+	return &campaign, nil
+}
+
+// expandEnv only replaces ${VAR}, a plain $ in a password or property value is
+// kept.
+func expandEnv(data string) string {
+	return envReference.ReplaceAllStringFunc(data, func(reference string) string {
+		return os.Getenv(reference[2 : len(reference)-1])
+	})
+}
+
+// resolvePath makes relative local paths relative to the campaign file, so
+// the campaign runs the same from any working directory. Only paths starting
+// with ./ or ../ are local, namespace/project is a GitHub repository.
+func resolvePath(baseDir string, path string) string {
+	if path == "." || path == ".." || strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../") {
+		return filepath.Join(baseDir, path)
+	}
+	return path
+}
+
+func resolveFile(baseDir string, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
+func (c *Campaign) Config(repository *CampaignRepository) Config {
+	config := Config{
+		SonarKey:       c.Sonar.Token,
+		SonarURL:       c.Sonar.URL,
+		SonarUser:      c.Sonar.User,
+		SonarPassword:  c.Sonar.Password,
+		Strategy:       c.Defaults.Strategy,
+		PeriodInterval: c.Defaults.Interval,
+		BatchSize:      c.Defaults.Batch,
+		TagPattern:     c.Defaults.TagPattern,
+		Resume:         c.Resume,
+		Parallel:       c.Parallel,
+		KeepGoing:      c.KeepGoing,
+		Languages:      c.Defaults.Languages,
+		History:        c.Defaults.History,
+		AliasFile:      c.Defaults.AliasFile,
+		Bots:           c.Defaults.Bots,
+		CloneTimeout:   c.Defaults.CloneTimeout,
+		ScanTimeout:    c.Defaults.ScanTimeout,
+
+		NewcomerEntrance:      c.Defaults.Newcomers.Entrance,
+		NewcomerMinCommits:    c.Defaults.Newcomers.MinCommits,
+		NewcomerMinActiveDays: c.Defaults.Newcomers.MinActiveDays,
+		LongTermMonths:        c.Defaults.Newcomers.LongTermMonths,
+		Attraction:            c.Defaults.Attraction,
+		AttractionDays:        c.Defaults.AttractionDays,
+	}
+	if c.Defaults.Worktree != nil {
+		config.Worktree = *c.Defaults.Worktree
+	}
+	if c.Defaults.MergeIdentities != nil {
+		config.MergeIdentities = *c.Defaults.MergeIdentities
+	}
+	if c.Defaults.IncludeBots != nil {
+		config.IncludeBots = *c.Defaults.IncludeBots
+	}
+
+	if repository.Strategy != "" {
+		config.Strategy = repository.Strategy
+	}
+	if repository.Interval != 0 {
+		config.PeriodInterval = repository.Interval
+	}
+	if repository.Batch != 0 {
+		config.BatchSize = repository.Batch
+	}
+	if repository.TagPattern != "" {
+		config.TagPattern = repository.TagPattern
+	}
+	if len(repository.Languages) > 0 {
+		config.Languages = repository.Languages
+	}
+	if repository.History != "" {
+		config.History = repository.History
+	}
+	if repository.Worktree != nil {
+		config.Worktree = *repository.Worktree
+	}
+	if repository.AliasFile != "" {
+		config.AliasFile = repository.AliasFile
+	}
+	if repository.MergeIdentities != nil {
+		config.MergeIdentities = *repository.MergeIdentities
+	}
+	if len(repository.Bots) > 0 {
+		config.Bots = repository.Bots
+	}
+	if repository.IncludeBots != nil {
+		config.IncludeBots = *repository.IncludeBots
+	}
+	if repository.Newcomers.Entrance != "" {
+		config.NewcomerEntrance = repository.Newcomers.Entrance
+	}
+	if repository.Newcomers.MinCommits != 0 {
+		config.NewcomerMinCommits = repository.Newcomers.MinCommits
+	}
+	if repository.Newcomers.MinActiveDays != 0 {
+		config.NewcomerMinActiveDays = repository.Newcomers.MinActiveDays
+	}
+	if repository.Newcomers.LongTermMonths != 0 {
+		config.LongTermMonths = repository.Newcomers.LongTermMonths
+	}
+	if repository.Attraction != "" {
+		config.Attraction = repository.Attraction
+	}
+	if repository.AttractionDays != 0 {
+		config.AttractionDays = repository.AttractionDays
+	}
+	if repository.CloneTimeout != 0 {
+		config.CloneTimeout = repository.CloneTimeout
+	}
+	if repository.ScanTimeout != 0 {
+		config.ScanTimeout = repository.ScanTimeout
+	}
+
+	// Repository exclusions add to the default ones, like both add to the
+	// built-in exclusions.
+	config.SonarProperties = make(map[string]string)
+	exclusions := make([]string, 0)
+	for _, options := range []CampaignOptions{c.Defaults, repository.CampaignOptions} {
+		for key, value := range options.Properties {
+			config.SonarProperties[key] = value
+		}
+		exclusions = append(exclusions, options.Exclusions...)
+	}
+	if len(exclusions) > 0 {
+		if propertyExclusions := config.SonarProperties["sonar.exclusions"]; propertyExclusions != "" {
+			exclusions = append([]string{propertyExclusions}, exclusions...)
+		}
+		config.SonarProperties["sonar.exclusions"] = strings.Join(exclusions, ",")
+	}
+
+	return config
+}
@@ -0,0 +1,72 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeCampaign(t *testing.T, content string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "studies")
+	err := os.MkdirAll(filepath.Join(dir, "myrepo"), 0775)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "campaign.yaml")
+	err = os.WriteFile(path, []byte(content), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadCampaignResolvesLocalRepositories(t *testing.T) {
+	path := writeCampaign(t, "repositories:\n  - ./myrepo\n  - repository: diegocsandrim/sonarminer\n")
+
+	campaign, err := LoadCampaign(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	local := campaign.Repositories[0].Repository
+	if local != filepath.Join(filepath.Dir(path), "myrepo") || !filepath.IsAbs(local) {
+		t.Errorf("local repository = %q, want the absolute path of myrepo", local)
+	}
+	if github := campaign.Repositories[1].Repository; github != "diegocsandrim/sonarminer" {
+		t.Errorf("github repository = %q, want it unchanged", github)
+	}
+}
+
+func TestLoadCampaignRejectsUnknownRepositoryFields(t *testing.T) {
+	for _, content := range []string{
+		"repositories:\n  - repository: a/b\n    stratgy: TAGS\n",
+		"repositories:\n  - repository: a/b\n    newcomers:\n      minCommit: 2\n",
+		"defaults:\n  stratgy: TAGS\nrepositories:\n  - a/b\n",
+	} {
+		_, err := LoadCampaign(writeCampaign(t, content))
+		if err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Errorf("LoadCampaign(%q) = %v, want an unknown field error", content, err)
+		}
+	}
+}
+
+func TestLoadCampaignOnlyExpandsBracedVariables(t *testing.T) {
+	os.Setenv("SONARMINER_TEST_TOKEN", "secret")
+	defer os.Unsetenv("SONARMINER_TEST_TOKEN")
+
+	path := writeCampaign(t, "sonar:\n  token: ${SONARMINER_TEST_TOKEN}\n  password: pa$$word$HOME\nrepositories:\n  - a/b\n")
+	campaign, err := LoadCampaign(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if campaign.Sonar.Token != "secret" {
+		t.Errorf("token = %q, want secret", campaign.Sonar.Token)
+	}
+	if campaign.Sonar.Password != "pa$$word$HOME" {
+		t.Errorf("password = %q, want it unchanged", campaign.Sonar.Password)
+	}
+}
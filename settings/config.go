@@ -1,9 +1,36 @@
 package settings
 
+import "time"
+
 type Config struct {
-	SonarKey       string
-	SonarURL       string
-	Strategy       string
-	PeriodInterval int
-	BatchSize      int
+	SonarKey        string
+	SonarURL        string
+	SonarUser       string
+	SonarPassword   string
+	Strategy        string
+	PeriodInterval  int
+	BatchSize       int
+	TagPattern      string
+	Resume          bool
+	Parallel        int
+	KeepGoing       bool
+	Worktree        bool
+	Languages       []string
+	History         string
+	AliasFile       string
+	MergeIdentities bool
+	Bots            []string
+	IncludeBots     bool
+	CloneTimeout    time.Duration
+	ScanTimeout     time.Duration
+
+	NewcomerEntrance      string
+	NewcomerMinCommits    int
+	NewcomerMinActiveDays int
+	LongTermMonths        int
+	Attraction            string
+	AttractionDays        int
+
+	SonarProperties           map[string]string
+	RepositorySonarProperties map[string]map[string]string
 }
@@ -0,0 +1,42 @@
+package settings
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LoadRepositoriesFile reads one repository per line, blank lines and text
+// after a # are ignored.
+func LoadRepositoriesFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read repositories file '%s': %w", path, err)
+	}
+	defer file.Close()
+
+	repositories := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+		if index := strings.Index(line, "#"); index >= 0 {
+			line = line[:index]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if len(strings.Fields(line)) > 1 {
+			return nil, fmt.Errorf("repositories file '%s' line %d: expected one repository per line: '%s'", path, lineNumber, line)
+		}
+		repositories = append(repositories, line)
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("fail to read repositories file '%s': %w", path, err)
+	}
+
+	return repositories, nil
+}
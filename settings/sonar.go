@@ -0,0 +1,56 @@
+package settings
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type SonarConfig struct {
+	Properties   map[string]string            `yaml:"properties"`
+	Repositories map[string]map[string]string `yaml:"repositories"`
+}
+
+func LoadSonarConfig(path string) (*SonarConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read sonar config file '%s': %w", path, err)
+	}
+
+	sonarConfig := SonarConfig{}
+	err = yaml.Unmarshal(data, &sonarConfig)
+	if err != nil {
+		return nil, fmt.Errorf("sonar config file '%s' is in a bad format: %w", path, err)
+	}
+
+	return &sonarConfig, nil
+}
+
+func ParseSonarProperties(values []string) (map[string]string, error) {
+	properties := make(map[string]string, len(values))
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("sonar property must be in format key=value: '%s'", value)
+		}
+		properties[strings.TrimSpace(parts[0])] = parts[1]
+	}
+	return properties, nil
+}
+
+func (c Config) SonarPropertiesFor(repositoryNames ...string) map[string]string {
+	properties := make(map[string]string, len(c.SonarProperties))
+	for key, value := range c.SonarProperties {
+		properties[key] = value
+	}
+
+	for _, repositoryName := range repositoryNames {
+		for key, value := range c.RepositorySonarProperties[repositoryName] {
+			properties[key] = value
+		}
+	}
+
+	return properties
+}
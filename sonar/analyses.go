@@ -0,0 +1,51 @@
+package sonar
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+type ProjectAnalysis struct {
+	Key            string
+	Date           time.Time
+	ProjectVersion string
+}
+
+func (c *Client) ProjectAnalyses(ctx context.Context, projectKey string) ([]*ProjectAnalysis, error) {
+	analyses := make([]*ProjectAnalysis, 0)
+
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("project", projectKey)
+		query.Set("ps", "500")
+		query.Set("p", fmt.Sprint(page))
+
+		data := struct {
+			Paging   paging `json:"paging"`
+			Analyses []struct {
+				Key            string `json:"key"`
+				Date           string `json:"date"`
+				ProjectVersion string `json:"projectVersion"`
+			} `json:"analyses"`
+		}{}
+
+		err := c.get(ctx, "api/project_analyses/search", query, &data)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, a := range data.Analyses {
+			date, err := parseDate(a.Date)
+			if err != nil {
+				return nil, err
+			}
+			analyses = append(analyses, &ProjectAnalysis{Key: a.Key, Date: date, ProjectVersion: a.ProjectVersion})
+		}
+
+		if !data.Paging.hasNextPage() {
+			return analyses, nil
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package sonar
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const (
+	CeTaskPending    = "PENDING"
+	CeTaskInProgress = "IN_PROGRESS"
+	CeTaskSuccess    = "SUCCESS"
+	CeTaskFailed     = "FAILED"
+	CeTaskCanceled   = "CANCELED"
+)
+
+type CeTask struct {
+	Id           string `json:"id"`
+	Type         string `json:"type"`
+	ComponentKey string `json:"componentKey"`
+	Status       string `json:"status"`
+	AnalysisId   string `json:"analysisId"`
+	ErrorMessage string `json:"errorMessage"`
+}
+
+func (t *CeTask) Done() bool {
+	return t.Status != CeTaskPending && t.Status != CeTaskInProgress
+}
+
+type CeTaskError struct {
+	TaskId  string
+	Status  string
+	Message string
+}
+
+func (e *CeTaskError) Error() string {
+	return fmt.Sprintf("compute engine task %s has finished with status %s: %s", e.TaskId, e.Status, e.Message)
+}
+
+func (c *Client) CeTask(ctx context.Context, taskId string) (*CeTask, error) {
+	query := url.Values{}
+	query.Set("id", taskId)
+
+	data := struct {
+		Task *CeTask `json:"task"`
+	}{}
+
+	err := c.get(ctx, "api/ce/task", query, &data)
+	if err != nil {
+		return nil, err
+	}
+	if data.Task == nil {
+		return nil, fmt.Errorf("compute engine task %s not found in api response", taskId)
+	}
+
+	return data.Task, nil
+}
+
+func (c *Client) WaitCeTask(ctx context.Context, taskId string, interval time.Duration, timeout time.Duration) (*CeTask, error) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		task, err := c.CeTask(ctx, taskId)
+		if err != nil {
+			return nil, fmt.Errorf("fail to get compute engine task %s: %w", taskId, err)
+		}
+
+		if task.Done() {
+			if task.Status != CeTaskSuccess {
+				return task, &CeTaskError{TaskId: task.Id, Status: task.Status, Message: task.ErrorMessage}
+			}
+			return task, nil
+		}
+
+		if time.Now().After(deadline) {
+			return task, fmt.Errorf("compute engine task %s is still %s after %s", taskId, task.Status, timeout)
+		}
+
+		err = sleep(ctx, interval)
+		if err != nil {
+			return task, err
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package sonar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout    = 30 * time.Second
+	defaultRetries    = 3
+	defaultRetryDelay = time.Second
+)
+
+type Client struct {
+	BaseURL    string
+	Username   string
+	Password   string
+	Retries    int
+	RetryDelay time.Duration
+	HTTPClient *http.Client
+}
+
+func NewClient(baseURL string, token string) *Client {
+	return NewClientWithPassword(baseURL, token, "")
+}
+
+func NewClientWithPassword(baseURL string, username string, password string) *Client {
+	c := Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		Username:   username,
+		Password:   password,
+		Retries:    defaultRetries,
+		RetryDelay: defaultRetryDelay,
+		HTTPClient: &http.Client{Timeout: defaultTimeout},
+	}
+	return &c
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, result interface{}) error {
+	return c.do(ctx, http.MethodGet, path, query, result)
+}
+
+func (c *Client) post(ctx context.Context, path string, query url.Values, result interface{}) error {
+	return c.do(ctx, http.MethodPost, path, query, result)
+}
+
+func (c *Client) do(ctx context.Context, method string, path string, query url.Values, result interface{}) error {
+	attempts := 1
+	if method == http.MethodGet {
+		attempts += c.Retries
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			log.Printf("retrying %s %s (%d/%d): %s", method, path, attempt-1, c.Retries, err.Error())
+			sleepErr := sleep(ctx, c.RetryDelay)
+			if sleepErr != nil {
+				return sleepErr
+			}
+		}
+
+		err = c.doOnce(ctx, method, path, query, result)
+		if err == nil || !retryable(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (c *Client) doOnce(ctx context.Context, method string, path string, query url.Values, result interface{}) error {
+	reqURL := fmt.Sprintf("%s/%s", c.BaseURL, path)
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, nil)
+	if err != nil {
+		return fmt.Errorf("fail to create a request to %s: %w", path, err)
+	}
+
+	if c.Username != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return &NetworkError{Method: method, Path: path, Err: err}
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return newAPIError(method, path, res)
+	}
+
+	if result == nil {
+		_, err = io.Copy(io.Discard, res.Body)
+		return err
+	}
+
+	err = json.NewDecoder(res.Body).Decode(result)
+	if err != nil {
+		return fmt.Errorf("fail to decode %s response from api: %w", path, err)
+	}
+
+	return nil
+}
+
+func sleep(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type paging struct {
+	PageIndex int `json:"pageIndex"`
+	PageSize  int `json:"pageSize"`
+	Total     int `json:"total"`
+}
+
+func (p paging) hasNextPage() bool {
+	return p.PageIndex*p.PageSize < p.Total
+}
+
+func parseDate(date string) (time.Time, error) {
+	parsed, err := time.Parse("2006-01-02T15:04:05-0700", date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("date is in a bad format: '%s': %w", date, err)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,88 @@
+package sonar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Messages   []string
+}
+
+func newAPIError(method string, path string, res *http.Response) *APIError {
+	e := APIError{
+		Method:     method,
+		Path:       path,
+		StatusCode: res.StatusCode,
+		Messages:   make([]string, 0),
+	}
+
+	body, _ := io.ReadAll(res.Body)
+	data := struct {
+		Errors []struct {
+			Msg string `json:"msg"`
+		} `json:"errors"`
+	}{}
+	if json.Unmarshal(body, &data) == nil {
+		for _, apiError := range data.Errors {
+			e.Messages = append(e.Messages, apiError.Msg)
+		}
+	}
+
+	return &e
+}
+
+func (e *APIError) Error() string {
+	if len(e.Messages) == 0 {
+		return fmt.Sprintf("sonarqube api %s %s failed, status code: %d", e.Method, e.Path, e.StatusCode)
+	}
+	return fmt.Sprintf("sonarqube api %s %s failed, status code: %d: %s", e.Method, e.Path, e.StatusCode, strings.Join(e.Messages, "; "))
+}
+
+type NetworkError struct {
+	Method string
+	Path   string
+	Err    error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("sonarqube api %s %s failed: %s", e.Method, e.Path, e.Err.Error())
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+func IsNotFound(err error) bool {
+	return hasStatusCode(err, http.StatusNotFound)
+}
+
+func IsUnauthorized(err error) bool {
+	return hasStatusCode(err, http.StatusUnauthorized) || hasStatusCode(err, http.StatusForbidden)
+}
+
+func hasStatusCode(err error, statusCode int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
+
+func retryable(err error) bool {
+	var networkErr *NetworkError
+	if errors.As(err, &networkErr) {
+		return true
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+
+	return false
+}
@@ -0,0 +1,101 @@
+package sonar
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type Measure struct {
+	Metric string
+	Value  string
+}
+
+type MeasureHistory struct {
+	Metric  string
+	History []*HistoryValue
+}
+
+type HistoryValue struct {
+	Date  time.Time
+	Value string
+}
+
+func (c *Client) ComponentMeasures(ctx context.Context, componentKey string, metrics []string) ([]*Measure, error) {
+	query := url.Values{}
+	query.Set("component", componentKey)
+	query.Set("metricKeys", strings.Join(metrics, ","))
+
+	data := struct {
+		Component struct {
+			Measures []struct {
+				Metric string `json:"metric"`
+				Value  string `json:"value"`
+			} `json:"measures"`
+		} `json:"component"`
+	}{}
+
+	err := c.get(ctx, "api/measures/component", query, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	measures := make([]*Measure, 0, len(data.Component.Measures))
+	for _, m := range data.Component.Measures {
+		measures = append(measures, &Measure{Metric: m.Metric, Value: m.Value})
+	}
+
+	return measures, nil
+}
+
+func (c *Client) MeasuresHistory(ctx context.Context, componentKey string, metrics []string) ([]*MeasureHistory, error) {
+	measuresByMetric := make(map[string]*MeasureHistory)
+	measures := make([]*MeasureHistory, 0, len(metrics))
+
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("component", componentKey)
+		query.Set("metrics", strings.Join(metrics, ","))
+		query.Set("ps", "1000")
+		query.Set("p", fmt.Sprint(page))
+
+		data := struct {
+			Paging   paging `json:"paging"`
+			Measures []struct {
+				Metric  string `json:"metric"`
+				History []struct {
+					Date  string `json:"date"`
+					Value string `json:"value"`
+				} `json:"history"`
+			} `json:"measures"`
+		}{}
+
+		err := c.get(ctx, "api/measures/search_history", query, &data)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range data.Measures {
+			measure, exists := measuresByMetric[m.Metric]
+			if !exists {
+				measure = &MeasureHistory{Metric: m.Metric, History: make([]*HistoryValue, 0, len(m.History))}
+				measuresByMetric[m.Metric] = measure
+				measures = append(measures, measure)
+			}
+
+			for _, h := range m.History {
+				date, err := parseDate(h.Date)
+				if err != nil {
+					return nil, err
+				}
+				measure.History = append(measure.History, &HistoryValue{Date: date, Value: h.Value})
+			}
+		}
+
+		if !data.Paging.hasNextPage() {
+			return measures, nil
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package sonar
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type Project struct {
+	Key              string
+	Name             string
+	LastAnalysisDate *time.Time
+}
+
+func (c *Client) SearchProjects(ctx context.Context, projectKeys ...string) ([]*Project, error) {
+	projects := make([]*Project, 0)
+
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("ps", "500")
+		query.Set("p", fmt.Sprint(page))
+		if len(projectKeys) > 0 {
+			query.Set("projects", strings.Join(projectKeys, ","))
+		}
+
+		data := struct {
+			Paging     paging `json:"paging"`
+			Components []struct {
+				Key              string `json:"key"`
+				Name             string `json:"name"`
+				LastAnalysisDate string `json:"lastAnalysisDate"`
+			} `json:"components"`
+		}{}
+
+		err := c.get(ctx, "api/projects/search", query, &data)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, component := range data.Components {
+			project := Project{Key: component.Key, Name: component.Name}
+			if component.LastAnalysisDate != "" {
+				date, err := parseDate(component.LastAnalysisDate)
+				if err != nil {
+					return nil, err
+				}
+				project.LastAnalysisDate = &date
+			}
+			projects = append(projects, &project)
+		}
+
+		if !data.Paging.hasNextPage() {
+			return projects, nil
+		}
+	}
+}
+
+func (c *Client) CreateProject(ctx context.Context, projectKey string, name string) error {
+	query := url.Values{}
+	query.Set("project", projectKey)
+	query.Set("name", name)
+
+	return c.post(ctx, "api/projects/create", query, nil)
+}
+
+func (c *Client) DeleteProject(ctx context.Context, projectKey string) error {
+	query := url.Values{}
+	query.Set("project", projectKey)
+
+	return c.post(ctx, "api/projects/delete", query, nil)
+}
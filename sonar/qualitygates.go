@@ -0,0 +1,49 @@
+package sonar
+
+import (
+	"context"
+	"net/url"
+)
+
+type QualityGateStatus struct {
+	Status     string
+	Conditions []*QualityGateCondition
+}
+
+type QualityGateCondition struct {
+	Status         string `json:"status"`
+	MetricKey      string `json:"metricKey"`
+	Comparator     string `json:"comparator"`
+	ErrorThreshold string `json:"errorThreshold"`
+	ActualValue    string `json:"actualValue"`
+}
+
+func (c *Client) ProjectQualityGateStatus(ctx context.Context, projectKey string) (*QualityGateStatus, error) {
+	query := url.Values{}
+	query.Set("projectKey", projectKey)
+
+	return c.qualityGateStatus(ctx, query)
+}
+
+func (c *Client) AnalysisQualityGateStatus(ctx context.Context, analysisId string) (*QualityGateStatus, error) {
+	query := url.Values{}
+	query.Set("analysisId", analysisId)
+
+	return c.qualityGateStatus(ctx, query)
+}
+
+func (c *Client) qualityGateStatus(ctx context.Context, query url.Values) (*QualityGateStatus, error) {
+	data := struct {
+		ProjectStatus struct {
+			Status     string                  `json:"status"`
+			Conditions []*QualityGateCondition `json:"conditions"`
+		} `json:"projectStatus"`
+	}{}
+
+	err := c.get(ctx, "api/qualitygates/project_status", query, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &QualityGateStatus{Status: data.ProjectStatus.Status, Conditions: data.ProjectStatus.Conditions}, nil
+}
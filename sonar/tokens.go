@@ -0,0 +1,34 @@
+package sonar
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+func (c *Client) GenerateToken(ctx context.Context, name string) (string, error) {
+	query := url.Values{}
+	query.Set("name", name)
+	query.Set("type", "USER_TOKEN")
+
+	data := struct {
+		Token string `json:"token"`
+	}{}
+
+	err := c.post(ctx, "api/user_tokens/generate", query, &data)
+	if err != nil {
+		return "", err
+	}
+	if data.Token == "" {
+		return "", fmt.Errorf("api has not returned the generated token")
+	}
+
+	return data.Token, nil
+}
+
+func (c *Client) RevokeToken(ctx context.Context, name string) error {
+	query := url.Values{}
+	query.Set("name", name)
+
+	return c.post(ctx, "api/user_tokens/revoke", query, nil)
+}
@@ -1,56 +1,40 @@
 package strategy
 
 import (
-	"fmt"
-	"log"
 	"time"
 
 	"github.com/diegocsandrim/sonarminer/git"
-	"github.com/diegocsandrim/sonarminer/qualityanalyzers"
 	"github.com/diegocsandrim/sonarminer/settings"
 )
 
-func AllCommits(namespace string, project string, config settings.Config) error {
-	gitRepo := git.NewGitRepo(namespace, project)
-
-	err := gitRepo.Clone()
-	if err != nil {
-		return fmt.Errorf("could not clone repo: %w", err)
-	}
-
-	err = gitRepo.LoadCommits()
-	if err != nil {
-		return fmt.Errorf("could not load commits: %w", err)
-	}
+func init() {
+	Register(&Registration{
+		Name:        "ALL",
+		Description: "analyse every commit of the repository",
+		Strategy:    &allCommits{},
+	})
+}
 
-	qualityAnalyzer, err := qualityanalyzers.CreateSonnarAnalyser(
-		qualityanalyzers.FormatProjectKey(namespace, project),
-		config.SonarKey,
-		config.SonarURL,
-		gitRepo.ProjectDir(),
-	)
-	if err != nil {
-		return err
-	}
+type allCommits struct{}
 
-	defer qualityAnalyzer.Close()
+func (s *allCommits) Validate(config settings.Config) error {
+	return nil
+}
 
+func (s *allCommits) Plan(gitRepo *git.GitRepo, config settings.Config) ([]*Analysis, error) {
 	commits := gitRepo.Commits()
 	day := time.Hour * 24
 	fakeDate := time.Now().Add(-day * time.Duration(len(commits)))
 
-	for i, commit := range commits {
-		shortCommitHash := commit.Hash[0:8]
-
-		log.Printf("Analysing commit %s (%d/%d) as if it was in %s\n", shortCommitHash, i+1, len(commits), commit.Date.String())
+	analyses := make([]*Analysis, 0, len(commits))
+	for _, commit := range commits {
 		contributors := 1
-
-		err = qualityAnalyzer.Run(shortCommitHash, fakeDate, contributors)
-		if err != nil {
-			return err
+		if !gitRepo.CountsAsContributor(commit.Contributor) {
+			contributors = 0
 		}
+		analyses = append(analyses, NewAnalysis(commit, fakeDate, contributors))
 		fakeDate = fakeDate.Add(day)
 	}
 
-	return nil
+	return analyses, nil
 }
@@ -0,0 +1,262 @@
+package strategy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/diegocsandrim/sonarminer/checkpoint"
+	"github.com/diegocsandrim/sonarminer/git"
+	"github.com/diegocsandrim/sonarminer/qualityanalyzers"
+	"github.com/diegocsandrim/sonarminer/settings"
+)
+
+func Prepare(ctx context.Context, remote *git.Remote, config settings.Config) (*git.GitRepo, []*Analysis, error) {
+	registration, err := Lookup(config.Strategy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = registration.Strategy.Validate(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gitRepo, err := Load(ctx, remote, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	analyses, err := registration.Strategy.Plan(gitRepo, config)
+	if err != nil {
+		gitRepo.Close()
+		return nil, nil, fmt.Errorf("could not plan analyses: %w", err)
+	}
+
+	return gitRepo, analyses, nil
+}
+
+// Load clones the repository and loads its commits, the caller must close
+// the returned repository.
+func Load(ctx context.Context, remote *git.Remote, config settings.Config) (*git.GitRepo, error) {
+	languages, err := git.NewLanguageSet(config.Languages)
+	if err != nil {
+		return nil, err
+	}
+
+	gitRepo := git.NewGitRepo(remote)
+	if config.Worktree {
+		gitRepo, err = git.NewWorktreeGitRepo(remote)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = clone(ctx, gitRepo, config.CloneTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("could not clone repo: %w", err)
+	}
+
+	options := git.LoadOptions{
+		Languages:       languages,
+		History:         config.History,
+		AliasFile:       config.AliasFile,
+		MergeIdentities: config.MergeIdentities,
+		Bots:            config.Bots,
+		IncludeBots:     config.IncludeBots,
+		Newcomers:       newcomerPolicy(config),
+		Attraction:      attractionModel(config),
+	}
+	err = gitRepo.LoadCommits(ctx, options)
+	if err != nil {
+		gitRepo.Close()
+		return nil, fmt.Errorf("could not load commits: %w", err)
+	}
+
+	return gitRepo, nil
+}
+
+func newcomerPolicy(config settings.Config) git.NewcomerPolicy {
+	return git.NewcomerPolicy{
+		Entrance:       config.NewcomerEntrance,
+		MinCommits:     config.NewcomerMinCommits,
+		MinActiveDays:  config.NewcomerMinActiveDays,
+		LongTermMonths: config.LongTermMonths,
+	}
+}
+
+func attractionModel(config settings.Config) git.AttractionModel {
+	return git.AttractionModel{
+		Mode: config.Attraction,
+		Days: config.AttractionDays,
+	}
+}
+
+// ValidateNewcomers checks the newcomer and attraction options before
+// cloning anything.
+func ValidateNewcomers(config settings.Config) error {
+	err := newcomerPolicy(config).Validate()
+	if err != nil {
+		return err
+	}
+	return attractionModel(config).Validate()
+}
+
+func clone(ctx context.Context, gitRepo *git.GitRepo, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	return gitRepo.Clone(ctx)
+}
+
+func Analyse(ctx context.Context, remote *git.Remote, config settings.Config) error {
+	gitRepo, analyses, err := Prepare(ctx, remote, config)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err := gitRepo.Close()
+		if err != nil {
+			log.Printf("failed to close repository %s: %s", remote, err.Error())
+		}
+	}()
+
+	languages, err := git.NewLanguageSet(config.Languages)
+	if err != nil {
+		return err
+	}
+
+	properties := qualityanalyzers.WithDefaults(
+		languages.Extensions(),
+		config.SonarPropertiesFor(remote.String(), remote.URL, remote.Key()),
+	)
+
+	projectKey := remote.Key()
+	qualityAnalyzer, err := qualityanalyzers.CreateSonnarAnalyser(
+		ctx,
+		projectKey,
+		config.SonarKey,
+		config.SonarURL,
+		gitRepo.ProjectDir(),
+		properties,
+	)
+	if err != nil {
+		return err
+	}
+	defer qualityAnalyzer.Close()
+
+	checkpoints, err := checkpoint.Open(git.GitBaseDir, projectKey, config.Strategy)
+	if err != nil {
+		return err
+	}
+
+	options, err := optionsFingerprint(config, properties)
+	if err != nil {
+		return err
+	}
+
+	if config.Resume {
+		err = checkpoints.Resume(options)
+	} else {
+		err = checkpoints.Reset(options)
+	}
+	if err != nil {
+		return err
+	}
+
+	for i, analysis := range analyses {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if checkpoints.Done(analysis.Commit.Hash) {
+			log.Printf("Skipping %s commit %s (%d/%d), already analysed\n", projectKey, analysis.Version, i+1, len(analyses))
+			continue
+		}
+
+		log.Printf("Analysing %s commit %s (%d/%d) - %s\n", projectKey, analysis.Version, i+1, len(analyses), analysis.Date.UTC())
+
+		err = gitRepo.Checkout(ctx, analysis.Commit.Hash)
+		if err != nil {
+			return fmt.Errorf("could not checkout to commit: %w", err)
+		}
+
+		err = scan(ctx, qualityAnalyzer, analysis, config.ScanTimeout)
+		if err != nil {
+			return fmt.Errorf("could not run analyser: %w", err)
+		}
+
+		err = checkpoints.Mark(&checkpoint.Entry{
+			Commit:       analysis.Commit.Hash,
+			Version:      analysis.Version,
+			Date:         analysis.Date.UTC(),
+			Contributors: analysis.Contributors,
+			AnalysedAt:   time.Now().UTC(),
+		})
+		if err != nil {
+			return fmt.Errorf("could not save checkpoint: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// optionsFingerprint identifies the options that change the planned commits
+// or their measures, so a resumed analysis does not mix two plans.
+func optionsFingerprint(config settings.Config, properties map[string]string) (string, error) {
+	options := struct {
+		Strategy        string
+		PeriodInterval  int
+		BatchSize       int
+		TagPattern      string
+		Languages       []string
+		History         string
+		AliasFile       string
+		MergeIdentities bool
+		Bots            []string
+		IncludeBots     bool
+		Newcomers       git.NewcomerPolicy
+		Attraction      git.AttractionModel
+		Properties      map[string]string
+	}{
+		Strategy:        strings.ToUpper(config.Strategy),
+		PeriodInterval:  config.PeriodInterval,
+		BatchSize:       config.BatchSize,
+		TagPattern:      config.TagPattern,
+		Languages:       config.Languages,
+		History:         config.History,
+		AliasFile:       config.AliasFile,
+		MergeIdentities: config.MergeIdentities,
+		Bots:            config.Bots,
+		IncludeBots:     config.IncludeBots,
+		Newcomers:       newcomerPolicy(config),
+		Attraction:      attractionModel(config),
+		Properties:      properties,
+	}
+
+	data, err := json.Marshal(options)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func scan(ctx context.Context, qualityAnalyzer *qualityanalyzers.Sonnar, analysis *Analysis, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	return qualityAnalyzer.Run(ctx, analysis.Version, analysis.Date, analysis.Contributors)
+}
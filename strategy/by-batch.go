@@ -2,47 +2,44 @@ package strategy
 
 import (
 	"fmt"
-	"log"
 	"math"
 	"sort"
 	"time"
 
 	"github.com/diegocsandrim/sonarminer/git"
-	"github.com/diegocsandrim/sonarminer/qualityanalyzers"
 	"github.com/diegocsandrim/sonarminer/settings"
 )
 
-func Batch(namespace string, project string, config settings.Config) error {
-	gitRepo := git.NewGitRepo(namespace, project)
+func init() {
+	Register(&Registration{
+		Name:        "BATCH",
+		Description: "group the commits that attracted new contributors in a fixed number of analyses",
+		Options:     []string{"batch"},
+		Strategy:    &byBatch{},
+	})
+}
 
-	err := gitRepo.Clone()
-	if err != nil {
-		return fmt.Errorf("could not clone repo: %w", err)
-	}
+type byBatch struct{}
 
-	err = gitRepo.LoadCommits()
-	if err != nil {
-		return fmt.Errorf("could not load commits: %w", err)
+func (s *byBatch) Validate(config settings.Config) error {
+	if config.BatchSize <= 0 {
+		return fmt.Errorf("batch must be greater than 0, got: %d", config.BatchSize)
 	}
+	return nil
+}
 
+func (s *byBatch) Plan(gitRepo *git.GitRepo, config settings.Config) ([]*Analysis, error) {
 	contributorAttractorCommits := gitRepo.ContributorAttractorCommits()
+	if len(contributorAttractorCommits) == 0 {
+		return []*Analysis{}, nil
+	}
+
 	sort.Slice(contributorAttractorCommits, func(i, j int) bool {
 		commitI := contributorAttractorCommits[i].Commit
 		commitJ := contributorAttractorCommits[j].Commit
 		return commitI.Id < commitJ.Id
 	})
 
-	qualityAnalyzer, err := qualityanalyzers.CreateSonnarAnalyser(
-		qualityanalyzers.FormatProjectKey(namespace, project),
-		config.SonarKey,
-		config.SonarURL,
-		gitRepo.ProjectDir(),
-	)
-	if err != nil {
-		return err
-	}
-	defer qualityAnalyzer.Close()
-
 	maxRunPerProject := float64(config.BatchSize)
 	contributorAttractorCommitsLen := float64(len(contributorAttractorCommits))
 	batchSize := int(math.Ceil(contributorAttractorCommitsLen / maxRunPerProject))
@@ -55,28 +52,17 @@ func Batch(namespace string, project string, config settings.Config) error {
 
 	day := time.Hour * 24
 	fakeDate := time.Now().Add(-day * time.Duration(maxRunPerProject+1))
-	for i, batch := range batches {
-		analyseCommit := batch[0].Commit
-
-		shortCommitHash := analyseCommit.Hash[0:8]
-		log.Printf("Analysing commit %s (batch %d/%d) - %s\n", shortCommitHash, i+1, len(batches), fakeDate.UTC())
 
+	analyses := make([]*Analysis, 0, len(batches))
+	for _, batch := range batches {
 		totalContributors := 0
 		for _, contributorAttractorCommit := range batch {
 			totalContributors += len(contributorAttractorCommit.Contributors)
 		}
 
-		err = gitRepo.Checkout(analyseCommit.Hash)
-		if err != nil {
-			return fmt.Errorf("could not checkout to commit: %w", err)
-		}
-
-		err = qualityAnalyzer.Run(shortCommitHash, fakeDate, totalContributors)
-		if err != nil {
-			return fmt.Errorf("could not run analyser: %w", err)
-		}
+		analyses = append(analyses, NewAnalysis(batch[0].Commit, fakeDate, totalContributors))
 		fakeDate = fakeDate.Add(day)
 	}
 
-	return nil
+	return analyses, nil
 }
@@ -1,29 +1,28 @@
 package strategy
 
 import (
-	"fmt"
-	"log"
 	"sort"
 	"time"
 
 	"github.com/diegocsandrim/sonarminer/git"
-	"github.com/diegocsandrim/sonarminer/qualityanalyzers"
 	"github.com/diegocsandrim/sonarminer/settings"
 )
 
-func InterestNewContributor(namespace string, project string, config settings.Config) error {
-	gitRepo := git.NewGitRepo(namespace, project)
+func init() {
+	Register(&Registration{
+		Name:        "INTEREST",
+		Description: "analyse every commit that attracted new contributors",
+		Strategy:    &byInterest{},
+	})
+}
 
-	err := gitRepo.Clone()
-	if err != nil {
-		return fmt.Errorf("could not clone repo: %w", err)
-	}
+type byInterest struct{}
 
-	err = gitRepo.LoadCommits()
-	if err != nil {
-		return fmt.Errorf("could not load commits: %w", err)
-	}
+func (s *byInterest) Validate(config settings.Config) error {
+	return nil
+}
 
+func (s *byInterest) Plan(gitRepo *git.GitRepo, config settings.Config) ([]*Analysis, error) {
 	contributorAttractorCommits := gitRepo.ContributorAttractorCommits()
 	sort.Slice(contributorAttractorCommits, func(i, j int) bool {
 		commitI := contributorAttractorCommits[i].Commit
@@ -31,35 +30,16 @@ func InterestNewContributor(namespace string, project string, config settings.Co
 		return commitI.Id < commitJ.Id
 	})
 
-	qualityAnalyzer, err := qualityanalyzers.CreateSonnarAnalyser(
-		qualityanalyzers.FormatProjectKey(namespace, project),
-		config.SonarKey,
-		config.SonarURL,
-		gitRepo.ProjectDir(),
-	)
-	if err != nil {
-		return err
-	}
-	defer qualityAnalyzer.Close()
-
 	day := time.Hour * 24
 	analysisDate := time.Now().UTC().Add(-day * time.Duration(len(contributorAttractorCommits)))
 
-	for i, contributorAttractorCommit := range contributorAttractorCommits {
+	analyses := make([]*Analysis, 0, len(contributorAttractorCommits))
+	for _, contributorAttractorCommit := range contributorAttractorCommits {
 		analysisDate = analysisDate.Add(day)
-		shortCommitHash := contributorAttractorCommit.Commit.Hash[0:8]
-		log.Printf("Analysing commit %s (%d/%d)\n", shortCommitHash, i+1, len(contributorAttractorCommits))
-		err = gitRepo.Checkout(contributorAttractorCommit.Commit.Hash)
-		if err != nil {
-			return fmt.Errorf("could not checkout to commit: %w", err)
-		}
-
 		attractedContributors := len(contributorAttractorCommit.Contributors)
 
-		err = qualityAnalyzer.Run(shortCommitHash, analysisDate, attractedContributors)
-		if err != nil {
-			return fmt.Errorf("could not run analyser: %w", err)
-		}
+		analyses = append(analyses, NewAnalysis(contributorAttractorCommit.Commit, analysisDate, attractedContributors))
 	}
-	return nil
+
+	return analyses, nil
 }
@@ -2,72 +2,53 @@ package strategy
 
 import (
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/diegocsandrim/sonarminer/git"
-	"github.com/diegocsandrim/sonarminer/qualityanalyzers"
 	"github.com/diegocsandrim/sonarminer/settings"
 )
 
-func AnalyseByPeriod(namespace string, project string, config settings.Config) error {
-	gitRepo := git.NewGitRepo(namespace, project)
-
-	err := gitRepo.Clone()
-	if err != nil {
-		return fmt.Errorf("could not clone repo: %w", err)
-	}
+func init() {
+	Register(&Registration{
+		Name:        "PERIOD",
+		Description: "analyse the earliest code commit of each period of months",
+		Options:     []string{"interval"},
+		Strategy:    &byPeriod{},
+	})
+}
 
-	err = gitRepo.LoadCommits()
-	if err != nil {
-		return fmt.Errorf("could not load commits: %w", err)
-	}
+type byPeriod struct{}
 
-	qualityAnalyzer, err := qualityanalyzers.CreateSonnarAnalyser(
-		qualityanalyzers.FormatProjectKey(namespace, project),
-		config.SonarKey,
-		config.SonarURL,
-		gitRepo.ProjectDir(),
-	)
-	if err != nil {
-		return err
+func (s *byPeriod) Validate(config settings.Config) error {
+	if config.PeriodInterval <= 0 {
+		return fmt.Errorf("interval must be greater than 0, got: %d", config.PeriodInterval)
 	}
+	return nil
+}
 
-	defer qualityAnalyzer.Close()
-
+func (s *byPeriod) Plan(gitRepo *git.GitRepo, config settings.Config) ([]*Analysis, error) {
 	period := config.PeriodInterval
 	monthlyCommits := gitRepo.CodeCommitsByPeriod(period)
 
-	for i, monthCommits := range monthlyCommits {
-		commit := getEarlyCommit(monthCommits.Commits)
-		contributors := uniqueContributors(monthCommits.Commits)
-
-		err = gitRepo.Checkout(commit.Hash)
-		if err != nil {
-			return err
-		}
-
-		shortCommitHash := commit.Hash[0:8]
+	analyses := make([]*Analysis, 0, len(monthlyCommits))
+	for _, monthCommits := range monthlyCommits {
+		commit := getEarlyCommit(monthCommits.LandingCommits)
+		contributors := uniqueContributors(gitRepo, monthCommits.Commits)
 
 		startTimestamp := time.Date(monthCommits.Month.Year, time.Month(monthCommits.Month.Period*period+1), 1, 0, 0, 0, 0, time.UTC)
 
-		log.Printf("Analysing commit %s (batch %d/%d) - %s\n", shortCommitHash, i+1, len(monthlyCommits), startTimestamp)
-
-		err = qualityAnalyzer.Run(shortCommitHash, startTimestamp, len(contributors))
-		if err != nil {
-			return err
-		}
+		analyses = append(analyses, NewAnalysis(commit, startTimestamp, len(contributors)))
 	}
 
-	return nil
+	return analyses, nil
 }
 
-func uniqueContributors(commits []*git.Commit) []*git.Contributor {
+func uniqueContributors(gitRepo *git.GitRepo, commits []*git.Commit) []*git.Contributor {
 	contruibutors := make([]*git.Contributor, 0, len(commits))
 	hash := make(map[string]interface{}, 0)
 
 	for _, commit := range commits {
-		if !commit.HasGoCode {
+		if !gitRepo.Newcomers().Considers(commit) || !gitRepo.CountsAsContributor(commit.Contributor) {
 			continue
 		}
 		_, exists := hash[commit.Contributor.Id]
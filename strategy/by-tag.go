@@ -0,0 +1,82 @@
+package strategy
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"time"
+
+	"github.com/diegocsandrim/sonarminer/git"
+	"github.com/diegocsandrim/sonarminer/settings"
+)
+
+func init() {
+	Register(&Registration{
+		Name:        "TAGS",
+		Description: "analyse every tagged release, counting the contributors since the previous tag",
+		Options:     []string{"tag-pattern"},
+		Strategy:    &byTag{},
+	})
+}
+
+type byTag struct{}
+
+func (s *byTag) Plan(gitRepo *git.GitRepo, config settings.Config) ([]*Analysis, error) {
+	tags, err := matchTags(gitRepo.Tags(), config.TagPattern)
+	if err != nil {
+		return nil, err
+	}
+
+	analyses := make([]*Analysis, 0, len(tags))
+	analysed := make(map[string]string)
+	var previous *git.Tag
+	for _, tag := range tags {
+		if name, exists := analysed[tag.Commit.Hash]; exists {
+			log.Printf("Skipping tag %s, the commit was already analysed as %s", tag.Name, name)
+			continue
+		}
+		analysed[tag.Commit.Hash] = tag.Name
+
+		var since *git.Commit
+		if previous != nil {
+			since = previous.Commit
+		}
+		contributors := uniqueContributors(gitRepo, gitRepo.CommitsBetween(since, tag.Commit))
+
+		// Sonarqube rejects an analysis older than the last one, tags created
+		// at the same second are moved forward.
+		date := tag.Date
+		if len(analyses) > 0 && !date.After(analyses[len(analyses)-1].Date) {
+			date = analyses[len(analyses)-1].Date.Add(time.Second)
+		}
+
+		analysis := NewAnalysis(tag.Commit, date, len(contributors))
+		analysis.Version = tag.Name
+		analyses = append(analyses, analysis)
+		previous = tag
+	}
+
+	return analyses, nil
+}
+
+func (s *byTag) Validate(config settings.Config) error {
+	_, err := path.Match(config.TagPattern, "")
+	if err != nil {
+		return fmt.Errorf("bad tag pattern: %s, %w", config.TagPattern, err)
+	}
+	return nil
+}
+
+func matchTags(tags []*git.Tag, pattern string) ([]*git.Tag, error) {
+	if pattern == "" {
+		return tags, nil
+	}
+
+	matched := make([]*git.Tag, 0, len(tags))
+	for _, tag := range tags {
+		if match, _ := path.Match(pattern, tag.Name); match {
+			matched = append(matched, tag)
+		}
+	}
+	return matched, nil
+}
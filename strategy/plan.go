@@ -0,0 +1,107 @@
+package strategy
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	PlanFormatTable = "table"
+	PlanFormatJSON  = "json"
+	PlanFormatCSV   = "csv"
+)
+
+type PlannedAnalysis struct {
+	ProjectKey     string    `json:"projectKey"`
+	Commit         string    `json:"commit"`
+	CommitDate     time.Time `json:"commitDate"`
+	ProjectVersion string    `json:"projectVersion"`
+	ProjectDate    time.Time `json:"projectDate"`
+	Contributors   int       `json:"contributors"`
+}
+
+func NewPlannedAnalysis(projectKey string, analysis *Analysis) *PlannedAnalysis {
+	p := PlannedAnalysis{
+		ProjectKey:     projectKey,
+		Commit:         analysis.Commit.Hash,
+		CommitDate:     analysis.Commit.Date.UTC(),
+		ProjectVersion: analysis.Version,
+		ProjectDate:    analysis.Date.UTC(),
+		Contributors:   analysis.Contributors,
+	}
+	return &p
+}
+
+func ValidatePlanFormat(format string) error {
+	switch format {
+	case PlanFormatTable, PlanFormatJSON, PlanFormatCSV:
+		return nil
+	default:
+		return fmt.Errorf("unknown plan format: %s, must be one of: %s, %s, %s", format, PlanFormatTable, PlanFormatJSON, PlanFormatCSV)
+	}
+}
+
+func WritePlan(w io.Writer, format string, plannedAnalyses []*PlannedAnalysis) error {
+	switch format {
+	case PlanFormatTable:
+		return writePlanTable(w, plannedAnalyses)
+	case PlanFormatJSON:
+		return writePlanJSON(w, plannedAnalyses)
+	case PlanFormatCSV:
+		return writePlanCSV(w, plannedAnalyses)
+	default:
+		return ValidatePlanFormat(format)
+	}
+}
+
+func writePlanTable(w io.Writer, plannedAnalyses []*PlannedAnalysis) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "PROJECT\tCOMMIT\tCOMMIT DATE\tVERSION\tPROJECT DATE\tCONTRIBUTORS")
+	for _, p := range plannedAnalyses {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%d\n",
+			p.ProjectKey,
+			p.Commit,
+			p.CommitDate.Format(time.RFC3339),
+			p.ProjectVersion,
+			p.ProjectDate.Format(time.RFC3339),
+			p.Contributors,
+		)
+	}
+	return table.Flush()
+}
+
+func writePlanJSON(w io.Writer, plannedAnalyses []*PlannedAnalysis) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(plannedAnalyses)
+}
+
+func writePlanCSV(w io.Writer, plannedAnalyses []*PlannedAnalysis) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{"project", "commit", "commit_date", "project_version", "project_date", "contributors"})
+	if err != nil {
+		return err
+	}
+
+	for _, p := range plannedAnalyses {
+		err = writer.Write([]string{
+			p.ProjectKey,
+			p.Commit,
+			p.CommitDate.Format(time.RFC3339),
+			p.ProjectVersion,
+			p.ProjectDate.Format(time.RFC3339),
+			strconv.Itoa(p.Contributors),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
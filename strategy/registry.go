@@ -0,0 +1,77 @@
+package strategy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/diegocsandrim/sonarminer/settings"
+)
+
+type Registration struct {
+	Name        string
+	Description string
+	Options     []string
+	Strategy    Strategy
+}
+
+var registry = make(map[string]*Registration)
+
+func Register(registration *Registration) {
+	name := strings.ToUpper(registration.Name)
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("strategy already registered: %s", name))
+	}
+	registry[name] = registration
+}
+
+func Lookup(name string) (*Registration, error) {
+	registration, exists := registry[strings.ToUpper(name)]
+	if !exists {
+		return nil, fmt.Errorf("unknown strategy: %s, must be one of: %s", name, strings.Join(Names(), ", "))
+	}
+	return registration, nil
+}
+
+// Validate checks the strategy exists and accepts its options.
+func Validate(config settings.Config) error {
+	registration, err := Lookup(config.Strategy)
+	if err != nil {
+		return err
+	}
+	return registration.Strategy.Validate(config)
+}
+
+func Registrations() []*Registration {
+	registrations := make([]*Registration, 0, len(registry))
+	for _, registration := range registry {
+		registrations = append(registrations, registration)
+	}
+
+	sort.Slice(registrations, func(i, j int) bool {
+		return registrations[i].Name < registrations[j].Name
+	})
+
+	return registrations
+}
+
+func Names() []string {
+	registrations := Registrations()
+	names := make([]string, 0, len(registrations))
+	for _, registration := range registrations {
+		names = append(names, registration.Name)
+	}
+	return names
+}
+
+func Usage() string {
+	var usage strings.Builder
+	usage.WriteString("Strategies:\n")
+	for _, registration := range Registrations() {
+		fmt.Fprintf(&usage, "   %-10s %s\n", registration.Name, registration.Description)
+		if len(registration.Options) > 0 {
+			fmt.Fprintf(&usage, "   %-10s options: --%s\n", "", strings.Join(registration.Options, ", --"))
+		}
+	}
+	return usage.String()
+}
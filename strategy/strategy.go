@@ -0,0 +1,31 @@
+package strategy
+
+import (
+	"time"
+
+	"github.com/diegocsandrim/sonarminer/git"
+	"github.com/diegocsandrim/sonarminer/settings"
+)
+
+type Analysis struct {
+	Commit       *git.Commit
+	Version      string
+	Date         time.Time
+	Contributors int
+}
+
+func NewAnalysis(commit *git.Commit, date time.Time, contributors int) *Analysis {
+	a := Analysis{
+		Commit:       commit,
+		Version:      commit.Hash[0:8],
+		Date:         date,
+		Contributors: contributors,
+	}
+	return &a
+}
+
+type Strategy interface {
+	// Validate checks the strategy options before any repository is cloned.
+	Validate(config settings.Config) error
+	Plan(gitRepo *git.GitRepo, config settings.Config) ([]*Analysis, error)
+}